@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"GoSnake/config"
+	"GoSnake/control"
+	"GoSnake/engine"
+	"GoSnake/event"
+)
+
+// benchUsage describes the arguments of the bench command
+const benchUsage = "[-bots name,...] [-games n] [-seed n] [-workers n] [-config file] [-json]"
+
+// stalled is the cause given to the games stopped because the bot stopped eating
+const stalled = "stalled"
+
+// benchGame is the outcome of a game played by a bot
+type benchGame struct {
+	score  int    // The final score
+	won    bool   // Whether the winning score was reached
+	moves  int    // The number of moves made
+	length int    // The final length of the snake
+	cause  string // What ended the game when it wasn't won
+}
+
+// benchReport sums up the games played by a bot
+type benchReport struct {
+	Bot         string         `json:"bot"`          // The name of the bot
+	Games       int            `json:"games"`        // The number of games played
+	MeanScore   float64        `json:"mean_score"`   // The average score
+	MedianScore float64        `json:"median_score"` // The median score
+	MaxScore    int            `json:"max_score"`    // The best score
+	WinRate     float64        `json:"win_rate"`     // The share of games won, from 0 to 1
+	MeanMoves   float64        `json:"mean_moves"`   // The average number of moves in a game
+	MeanLength  float64        `json:"mean_length"`  // The average final length of the snake
+	Deaths      map[string]int `json:"deaths"`       // The number of games ended by each cause, other than winning
+}
+
+// bench plays seeded games without a window for each bot and compares how they did
+func bench(args []string) int {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	botNames := flags.String("bots", strings.Join(control.Bots, ","), "bots to compare, separated by commas")
+	games := flags.Int("games", 100, "number of games played by each bot, a game lasts longer the more the bot must eat to win")
+	seed := flags.Int64("seed", 1, "seed of the first game, each game uses the next one and every bot plays the same seeds")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of games played at the same time")
+	configPath := flags.String("config", config.Path, "configuration file with the rules, the bots play alone")
+	asJSON := flags.Bool("json", false, "print the results as JSON instead of a table")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 || *games <= 0 || *workers <= 0 {
+		fmt.Fprintf(os.Stderr, "Usage: gosnake bench %s\n", benchUsage)
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cfg.Rules.Players = 0
+	rules, err := cfg.EngineRules()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	bots := strings.Split(*botNames, ",")
+	for _, name := range bots {
+		if _, err := control.NewBot(name); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	// Play every game of every bot on a pool of workers
+	type job struct{ bot, game int }
+	results := make([][]benchGame, len(bots))
+	for i := range results {
+		results[i] = make([]benchGame, *games)
+	}
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				bot, _ := control.NewBot(bots[j.bot]) // A new one each game, bots may remember things about the board
+				results[j.bot][j.game] = playBench(rules, *seed+int64(j.game), bot)
+			}
+		}()
+	}
+	for b := range bots {
+		for g := 0; g < *games; g++ {
+			jobs <- job{bot: b, game: g}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	reports := make([]benchReport, len(bots))
+	for i, name := range bots {
+		reports[i] = summarize(name, results[i])
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reports); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	printBench(reports)
+	return 0
+}
+
+// playBench plays a game with a bot until it ends, or until the bot goes as many moves as there are cells
+// on the board twice without eating, enough for any bot going somewhere to reach the food
+func playBench(rules engine.Rules, seed int64, bot control.Controller) benchGame {
+	state := engine.NewState(rules, seed)
+	limit := 2 * rules.Board.Width * rules.Board.Height
+	last := 0
+	game := benchGame{cause: stalled}
+	for !state.GameOver && !state.GameWon && state.Tick-last < limit {
+		var input engine.Input
+		for _, direction := range bot.Turns(control.NewView(state, 0)) {
+			input.Add(0, direction)
+		}
+		var events []event.Event
+		state, events = engine.Step(state, input)
+		for _, e := range events {
+			switch e := e.(type) {
+			case event.SnakeDied:
+				game.cause = e.Cause.String()
+			case event.FoodEaten, event.BonusEaten:
+				last = state.Tick
+			}
+		}
+	}
+	game.score = state.Scores[0]
+	game.won = state.GameWon
+	game.moves = state.Tick
+	game.length = len(state.Snakes[0].Body)
+	if game.won {
+		game.cause = ""
+	}
+	return game
+}
+
+// summarize sums up the games of a bot
+func summarize(name string, games []benchGame) benchReport {
+	report := benchReport{Bot: name, Games: len(games), Deaths: map[string]int{}}
+	scores := make([]int, len(games))
+	wins := 0
+	for i, g := range games {
+		scores[i] = g.score
+		report.MeanScore += float64(g.score)
+		report.MaxScore = max(report.MaxScore, g.score)
+		report.MeanMoves += float64(g.moves)
+		report.MeanLength += float64(g.length)
+		if g.won {
+			wins++
+		} else {
+			report.Deaths[g.cause]++
+		}
+	}
+	n := float64(len(games))
+	report.MeanScore /= n
+	report.MeanMoves /= n
+	report.MeanLength /= n
+	report.WinRate = float64(wins) / n
+
+	sort.Ints(scores)
+	middle := len(scores) / 2
+	report.MedianScore = float64(scores[middle])
+	if len(scores)%2 == 0 {
+		report.MedianScore = float64(scores[middle-1]+scores[middle]) / 2
+	}
+	return report
+}
+
+// printBench prints the reports as a table, the death causes as shares of the games
+func printBench(reports []benchReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Bot\tGames\tMean\tMedian\tMax\tWin rate\tMoves\tLength\tDeaths")
+	for _, r := range reports {
+		fmt.Fprintf(w, "%s\t%d\t%.1f\t%.1f\t%d\t%.1f%%\t%.0f\t%.1f\t%s\n",
+			r.Bot, r.Games, r.MeanScore, r.MedianScore, r.MaxScore, 100*r.WinRate, r.MeanMoves, r.MeanLength, deathShares(r))
+	}
+	w.Flush()
+}
+
+// deathShares lists the causes of death of a report with the share of the games each one ended, sorted by cause
+func deathShares(r benchReport) string {
+	causes := make([]string, 0, len(r.Deaths))
+	for cause := range r.Deaths {
+		causes = append(causes, cause)
+	}
+	sort.Strings(causes)
+	shares := make([]string, len(causes))
+	for i, cause := range causes {
+		shares[i] = fmt.Sprintf("%s %.0f%%", cause, 100*float64(r.Deaths[cause])/float64(r.Games))
+	}
+	return strings.Join(shares, ", ")
+}
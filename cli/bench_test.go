@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"GoSnake/control"
+	"GoSnake/engine"
+	"GoSnake/vars"
+)
+
+// straight is a bot that never turns
+type straight struct{}
+
+func (straight) Turns(view control.View) []vars.Point {
+	return nil
+}
+
+// clockwise is a bot turning clockwise on every move, going round the same four cells
+type clockwise struct{}
+
+func (clockwise) Turns(view control.View) []vars.Point {
+	d := view.Direction()
+	return []vars.Point{{X: -d.Y, Y: d.X}}
+}
+
+func TestPlayBench(t *testing.T) {
+	rules := engine.DefaultRules()
+	rules.Board.Width, rules.Board.Height = 8, 6
+
+	// The food must be out of the way of the bot going round, or it would eat it
+	loop := map[vars.Point]bool{{X: 4, Y: 3}: true, {X: 4, Y: 4}: true, {X: 3, Y: 4}: true, {X: 3, Y: 3}: true}
+	seed := int64(1)
+	for loop[engine.NewState(rules, seed).Foods[0].Position] {
+		seed++
+	}
+
+	tests := []struct {
+		name string
+		bot  control.Controller
+		want benchGame
+	}{
+		{"into a wall", straight{}, benchGame{moves: 4, length: 1, cause: "hit wall"}},
+		{"going round", clockwise{}, benchGame{moves: 2 * 8 * 6, length: 1, cause: stalled}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := playBench(rules, seed, tt.bot); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name  string
+		games []benchGame
+		want  benchReport
+	}{
+		{"odd count", []benchGame{
+			{score: 7, moves: 100, length: 8, cause: "hit self"},
+			{score: 25, won: true, moves: 300, length: 26},
+			{score: 3, moves: 20, length: 4, cause: "hit wall"},
+		}, benchReport{Bot: "bot", Games: 3, MeanScore: 35.0 / 3, MedianScore: 7, MaxScore: 25, WinRate: 1.0 / 3, MeanMoves: 140, MeanLength: 38.0 / 3,
+			Deaths: map[string]int{"hit self": 1, "hit wall": 1}}},
+		{"even count", []benchGame{
+			{score: 4, moves: 10, length: 5, cause: stalled},
+			{score: 1, moves: 10, length: 2, cause: "hit wall"},
+			{score: 9, moves: 50, length: 10, cause: "hit wall"},
+			{score: 6, moves: 30, length: 7, cause: "hit wall"},
+		}, benchReport{Bot: "bot", Games: 4, MeanScore: 5, MedianScore: 5, MaxScore: 9, MeanMoves: 25, MeanLength: 6,
+			Deaths: map[string]int{stalled: 1, "hit wall": 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarize("bot", tt.games); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeathShares(t *testing.T) {
+	tests := []struct {
+		name   string
+		report benchReport
+		want   string
+	}{
+		{"every game won", benchReport{Games: 3, Deaths: map[string]int{}}, ""},
+		{"one cause", benchReport{Games: 4, Deaths: map[string]int{"hit wall": 1}}, "hit wall 25%"},
+		{"sorted by cause", benchReport{Games: 3, Deaths: map[string]int{stalled: 1, "hit wall": 2}}, "hit wall 67%, stalled 33%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deathShares(tt.report); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
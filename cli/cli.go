@@ -0,0 +1,50 @@
+// Package cli implements the gosnake commands that run without a window,
+// so they work on machines without a display
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command is a subcommand of gosnake
+type command struct {
+	usage string                  // The arguments of the command
+	run   func(args []string) int // Runs the command and returns the exit code
+}
+
+// commands lists the commands by name
+var commands = map[string]command{
+	"bench":  {usage: benchUsage, run: bench},
+	"gym":    {usage: gymUsage, run: gymCommand},
+	"server": {usage: serverUsage, run: server},
+	"verify": {usage: verifyUsage, run: verify},
+}
+
+// Run runs the command named by the first argument and returns its exit code,
+// ok is false when the arguments don't name a command
+func Run(args []string) (code int, ok bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return 0, false
+	}
+	return cmd.run(args[1:]), true
+}
+
+// Usage prints the list of commands
+func Usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  gosnake %s %s\n", name, commands[name].usage)
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"GoSnake/config"
+	"GoSnake/gym"
+)
+
+// gymUsage describes the arguments of the gym command
+const gymUsage = "[-addr host:port] [-config file] [-seed n]"
+
+// gymCommand lets agents play the game through the environment protocol, on stdin and stdout or on a socket
+func gymCommand(args []string) int {
+	flags := flag.NewFlagSet("gym", flag.ContinueOnError)
+	addr := flags.String("addr", "", "address to take agents on, each with an environment of its own, instead of stdin and stdout")
+	configPath := flags.String("config", config.Path, "configuration file with the rules, the snake plays alone")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "seed of the first game, each reset without a seed uses the next one")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Usage: gosnake gym %s\n", gymUsage)
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cfg.Rules.Players = 0
+	rules, err := cfg.EngineRules()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if *addr == "" {
+		if err := gym.Serve(os.Stdin, os.Stdout, gym.NewEnv(rules, *seed)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	s, err := gym.Listen(*addr, rules, *seed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Waiting for agents on %s\n", s.Addr())
+	if err := s.Serve(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
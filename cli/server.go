@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"GoSnake/config"
+	"GoSnake/netplay"
+)
+
+// serverUsage describes the arguments of the server command
+const serverUsage = "[-addr host:port] [-players n] [-config file] [-seed n]"
+
+// server runs networked games until it's interrupted
+func server(args []string) int {
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	addr := flags.String("addr", netplay.DefaultAddr, "address to listen on")
+	players := flags.Int("players", 2, "number of snakes on the board")
+	configPath := flags.String("config", config.Path, "configuration file with the rules")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "seed of the first game, each game uses the next one")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Usage: gosnake server %s\n", serverUsage)
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	cfg.Rules.Players = *players
+	rules, err := cfg.EngineRules()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	s, err := netplay.Listen(*addr, rules, *seed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Waiting for %d players on %s\n", rules.PlayerCount(), s.Addr())
+	if err := s.Serve(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"GoSnake/replay"
+)
+
+// verifyUsage describes the arguments of the verify command
+const verifyUsage = "<replay> <claimed score>"
+
+// verify re-simulates a replay and checks it reaches the claimed score
+func verify(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintf(os.Stderr, "Usage: gosnake verify %s\n", verifyUsage)
+		return 2
+	}
+	claimed, err := strconv.Atoi(args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid claimed score %q\n", args[1])
+		return 2
+	}
+	r, err := replay.Read(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	result := replay.Simulate(r)
+	fmt.Printf("Seed:     %d\n", r.Seed)
+	fmt.Printf("Score:    %d\n", result.Score)
+	fmt.Printf("Length:   %d\n", result.Length)
+	fmt.Printf("Duration: %v (%d moves)\n", result.Duration.Round(100*time.Millisecond), result.Ticks)
+	switch {
+	case result.Won:
+		fmt.Println("Result:   won")
+	case result.Died:
+		fmt.Printf("Result:   died (%v)\n", result.Cause)
+	default:
+		fmt.Println("Result:   unfinished")
+	}
+
+	if result.Score != claimed {
+		fmt.Printf("The claimed score %d doesn't match the replay\n", claimed)
+		return 1
+	}
+	fmt.Printf("The claimed score %d is verified\n", claimed)
+	return 0
+}
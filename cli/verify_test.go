@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"GoSnake/engine"
+	"GoSnake/replay"
+)
+
+func TestVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.gsr")
+	rec := replay.New(engine.DefaultRules(), 3)
+	if err := replay.Write(path, rec); err != nil {
+		t.Fatal(err)
+	}
+	score := strconv.Itoa(replay.Simulate(rec).Score)
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"right score", []string{path, score}, 0},
+		{"wrong score", []string{path, score + "1"}, 1},
+		{"not a score", []string{path, "many"}, 2},
+		{"missing replay", []string{filepath.Join(t.TempDir(), "none.gsr"), score}, 2},
+		{"missing score", []string{path}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, ok := Run(append([]string{"verify"}, tt.args...)); !ok || code != tt.code {
+				t.Errorf("exit code %d, want %d", code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+// Command gosnake runs the GoSnake tools that don't need a window, such as
+// verifying a score, on machines without a display
+package main
+
+import (
+	"os"
+
+	"GoSnake/cli"
+)
+
+// main runs the command given on the command line
+func main() {
+	code, ok := cli.Run(os.Args[1:])
+	if !ok {
+		cli.Usage()
+		os.Exit(2)
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,379 @@
+// Package config loads the settings of the game from a JSON file, anything
+// missing from the file keeps the default value
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"GoSnake/engine"
+	"GoSnake/food"
+	"GoSnake/level"
+	"GoSnake/vars"
+)
+
+// Path is where the configuration is looked for, next to the scores
+const Path = "config.json"
+
+// Config holds every setting of the game
+type Config struct {
+	Rules     Rules    `json:"rules"`      // How the game plays
+	Visuals   Visuals  `json:"visuals"`    // How the game looks
+	Controls  Controls `json:"controls"`   // Which keys do what
+	PlayerTwo Controls `json:"player_two"` // Which keys turn the second player's snake, only the directions are used
+	Gamepad   Gamepad  `json:"gamepad"`    // Which gamepad buttons and sticks do what
+	Audio     Audio    `json:"audio"`      // Which sounds are played
+}
+
+// Rules are the settings that change how the game plays
+type Rules struct {
+	Width         int               `json:"width"`             // The number of cells in a row
+	Height        int               `json:"height"`            // The number of cells in a column
+	Wrap          bool              `json:"wrap"`              // Whether the snake goes through the edges instead of hitting them
+	StartInterval Milliseconds      `json:"start_interval_ms"` // The time between two moves at the start of a game
+	MinInterval   Milliseconds      `json:"min_interval_ms"`   // The shortest time between two moves
+	IntervalStep  Milliseconds      `json:"interval_step_ms"`  // How much faster the snake gets each time it eats
+	WinScore      int               `json:"win_score"`         // The score needed to win the game
+	Level         string            `json:"level"`             // The level file to play in, empty for an empty board
+	Foods         []FoodType        `json:"foods"`             // The kinds of food that can appear, empty for the classic food only
+	Bonus         engine.BonusRules `json:"bonus"`             // When the bonus food appears and what it's worth, a chance of 0 turns it off
+	Portals       int               `json:"portals"`           // The number of portals placed at random
+	Players       int               `json:"players"`           // The number of snakes on the board, 0 for a single one
+	SeparateFood  bool              `json:"separate_food"`     // Whether each player has their own food instead of sharing one
+}
+
+// FoodType describes a kind of food and what eating it does
+type FoodType struct {
+	Kind     string       `json:"kind"`        // The name of the kind, the visuals and audio use it to pick a color and a sound
+	Weight   int          `json:"weight"`      // How often it appears compared to the other kinds
+	Points   int          `json:"points"`      // The points it's worth
+	Grow     int          `json:"grow"`        // The segments the snake gains, or loses when negative
+	Interval Milliseconds `json:"interval_ms"` // How much the time between two moves changes, negative speeds the snake up
+	Deadly   bool         `json:"deadly"`      // Whether eating it kills the snake
+	Lifetime int          `json:"lifetime"`    // The number of moves a deadly food stays, 0 for the width and height of the board
+}
+
+// Visuals are the settings that change how the game looks
+type Visuals struct {
+	TileSize   int              `json:"tile_size"`  // The size of a cell on screen, in pixels
+	Background Color            `json:"background"` // The color of the board
+	Snake      Color            `json:"snake"`      // The color of the snake
+	Snakes     []Color          `json:"snakes"`     // The colors of the other players' snakes, the snake color for the missing ones
+	Food       Color            `json:"food"`       // The color of the food
+	Wall       Color            `json:"wall"`       // The color of the walls of a level
+	Foods      map[string]Color `json:"foods"`      // The color of each kind of food, the food color for the others
+	Bonus      Color            `json:"bonus"`      // The color of the bonus food and of its timer
+	Portal     Color            `json:"portal"`     // The color of the portals
+	Text       Color            `json:"text"`       // The color of the text
+}
+
+// Controls lists the names of the keys bound to each action
+type Controls struct {
+	Up      []string `json:"up"`      // Turn up
+	Down    []string `json:"down"`    // Turn down
+	Left    []string `json:"left"`    // Turn left
+	Right   []string `json:"right"`   // Turn right
+	Pause   []string `json:"pause"`   // Pause and resume the game
+	Start   []string `json:"start"`   // Start the game from the title screen
+	Restart []string `json:"restart"` // Restart the game
+	Menu    []string `json:"menu"`    // Open and close the menu
+}
+
+// Actions lists the names of the actions in the controls, in the order they're shown
+var Actions = []string{"up", "down", "left", "right", "pause", "start", "restart", "menu"}
+
+// Keys returns the keys bound to an action named in Actions, nil for an unknown action
+func (c *Controls) Keys(action string) *[]string {
+	switch action {
+	case "up":
+		return &c.Up
+	case "down":
+		return &c.Down
+	case "left":
+		return &c.Left
+	case "right":
+		return &c.Right
+	case "pause":
+		return &c.Pause
+	case "start":
+		return &c.Start
+	case "restart":
+		return &c.Restart
+	case "menu":
+		return &c.Menu
+	}
+	return nil
+}
+
+// Gamepad lists the gamepad buttons bound to each action, and the stick used to turn. Buttons are numbered
+// as the system reports them, the defaults fit the usual layout of an Xbox controller
+type Gamepad struct {
+	DeadZone float64 `json:"dead_zone"` // How far the stick must be pushed before it turns the snake, from 0 to 1
+	StickX   int     `json:"stick_x"`   // The axis of the stick going left and right
+	StickY   int     `json:"stick_y"`   // The axis of the stick going up and down
+	Up       []int   `json:"up"`        // Turn up, usually the d-pad
+	Down     []int   `json:"down"`      // Turn down
+	Left     []int   `json:"left"`      // Turn left
+	Right    []int   `json:"right"`     // Turn right
+	Pause    []int   `json:"pause"`     // Pause and resume the game
+	Start    []int   `json:"start"`     // Start the game and confirm in menus
+	Restart  []int   `json:"restart"`   // Restart the game
+	Menu     []int   `json:"menu"`      // Open and close the menu
+}
+
+// Buttons returns the buttons bound to an action named in Actions, nil for an unknown action
+func (g *Gamepad) Buttons(action string) *[]int {
+	switch action {
+	case "up":
+		return &g.Up
+	case "down":
+		return &g.Down
+	case "left":
+		return &g.Left
+	case "right":
+		return &g.Right
+	case "pause":
+		return &g.Pause
+	case "start":
+		return &g.Start
+	case "restart":
+		return &g.Restart
+	case "menu":
+		return &g.Menu
+	}
+	return nil
+}
+
+// Audio are the settings of the sound effects
+type Audio struct {
+	Eat    string            `json:"eat"`    // The sound played when the snake eats
+	Lose   string            `json:"lose"`   // The sound played when the snake dies
+	Win    string            `json:"win"`    // The sound played when the player wins
+	Foods  map[string]string `json:"foods"`  // The sound played when the snake eats each kind of food, the eat sound for the others
+	Volume float64           `json:"volume"` // The volume of every sound, from 0 to 1
+}
+
+// Default returns the settings the game uses without a configuration file
+func Default() Config {
+	rules := engine.DefaultRules()
+	return Config{
+		Rules: Rules{
+			Width:         rules.Board.Width,
+			Height:        rules.Board.Height,
+			Wrap:          rules.Board.Wrap,
+			StartInterval: Milliseconds(rules.StartInterval),
+			MinInterval:   Milliseconds(rules.MinInterval),
+			IntervalStep:  Milliseconds(rules.IntervalStep),
+			WinScore:      rules.WinScore,
+		},
+		Visuals: Visuals{
+			TileSize:   vars.DefaultTileSize,
+			Background: Color{154, 198, 0, 255},
+			Snake:      Color{33, 50, 15, 255},
+			Snakes:     []Color{{30, 58, 138, 255}, {124, 45, 18, 255}, {88, 28, 135, 255}},
+			Food:       Color{231, 71, 29, 255},
+			Wall:       Color{74, 93, 35, 255},
+			Bonus:      Color{255, 215, 0, 255},
+			Portal:     Color{63, 81, 181, 255},
+			Text:       Color{255, 255, 255, 255},
+		},
+		Controls: Controls{
+			Up:      []string{"W", "Up"},
+			Down:    []string{"S", "Down"},
+			Left:    []string{"A", "Left"},
+			Right:   []string{"D", "Right"},
+			Pause:   []string{"P"},
+			Start:   []string{"Space"},
+			Restart: []string{"R"},
+			Menu:    []string{"Escape"},
+		},
+		PlayerTwo: Controls{
+			Up:    []string{"Up"},
+			Down:  []string{"Down"},
+			Left:  []string{"Left"},
+			Right: []string{"Right"},
+		},
+		Gamepad: Gamepad{
+			DeadZone: 0.5,
+			StickX:   0,
+			StickY:   1,
+			Up:       []int{11},
+			Down:     []int{13},
+			Left:     []int{14},
+			Right:    []int{12},
+			Pause:    []int{7},
+			Start:    []int{0},
+			Restart:  []int{3},
+			Menu:     []int{6},
+		},
+		Audio: Audio{
+			Eat:    "sound/eatSound.mp3",
+			Lose:   "sound/loseSound.mp3",
+			Win:    "sound/winSound.mp3",
+			Volume: 1,
+		},
+	}
+}
+
+// Save writes the configuration at the given path
+func Save(path string, cfg Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// SaveControls replaces the controls in the configuration file at the given path. The other settings are
+// written back as they are in the file, those left out stay left out so they keep following the defaults
+func SaveControls(path string, controls Controls) error {
+	// Loading checks the file is a valid configuration before it's overwritten
+	if _, err := Load(path); err != nil {
+		return err
+	}
+	fields := map[string]json.RawMessage{}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	if fields["controls"], err = json.Marshal(controls); err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Load reads the configuration at the given path over the defaults and validates it.
+// A missing file isn't an error, the defaults are returned
+func Load(path string) (Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields() // Catch misspelled settings instead of silently ignoring them
+	if err := decoder.Decode(&cfg); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := position(data, syntaxErr.Offset-1) // The offset is just after the faulty character
+			return cfg, fmt.Errorf("%s:%d:%d: %v", path, line, column, err)
+		}
+		return cfg, fmt.Errorf("%s: %v", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks every setting has a usable value
+func (c Config) Validate() error {
+	rules, err := c.EngineRules()
+	if err != nil {
+		return err
+	}
+	if err := rules.Validate(); err != nil {
+		return fmt.Errorf("rules: %w", err)
+	}
+	if c.Visuals.TileSize <= 0 {
+		return fmt.Errorf("visuals.tile_size must be positive, got %d", c.Visuals.TileSize)
+	}
+	for _, action := range Actions {
+		if len(*c.Controls.Keys(action)) == 0 {
+			return fmt.Errorf("controls.%s needs at least one key", action)
+		}
+	}
+	if rules.PlayerCount() > 1 {
+		for _, action := range Actions[:4] { // The directions come first
+			if len(*c.PlayerTwo.Keys(action)) == 0 {
+				return fmt.Errorf("player_two.%s needs at least one key", action)
+			}
+		}
+	}
+	if c.Gamepad.DeadZone < 0 || c.Gamepad.DeadZone >= 1 {
+		return fmt.Errorf("gamepad.dead_zone must be at least 0 and less than 1, got %v", c.Gamepad.DeadZone)
+	}
+	if c.Gamepad.StickX < 0 || c.Gamepad.StickY < 0 {
+		return fmt.Errorf("gamepad.stick_x and gamepad.stick_y must not be negative")
+	}
+	for _, action := range Actions {
+		for _, button := range *c.Gamepad.Buttons(action) {
+			if button < 0 {
+				return fmt.Errorf("gamepad.%s: invalid button %d", action, button)
+			}
+		}
+	}
+	if c.Audio.Volume < 0 || c.Audio.Volume > 1 {
+		return fmt.Errorf("audio.volume must be between 0 and 1, got %v", c.Audio.Volume)
+	}
+	return nil
+}
+
+// EngineRules returns the rules to give the engine, loading the level if there's one
+func (c Config) EngineRules() (engine.Rules, error) {
+	rules := engine.Rules{
+		Board:         c.Board(),
+		StartInterval: c.Rules.StartInterval.Duration(),
+		MinInterval:   c.Rules.MinInterval.Duration(),
+		IntervalStep:  c.Rules.IntervalStep.Duration(),
+		WinScore:      c.Rules.WinScore,
+		Bonus:         c.Rules.Bonus,
+		Portals:       c.Rules.Portals,
+		Players:       c.Rules.Players,
+		SeparateFood:  c.Rules.SeparateFood,
+	}
+	for _, t := range c.Rules.Foods {
+		rules.Foods = append(rules.Foods, food.Type{
+			Kind:     t.Kind,
+			Weight:   t.Weight,
+			Points:   t.Points,
+			Grow:     t.Grow,
+			Interval: t.Interval.Duration(),
+			Deadly:   t.Deadly,
+			Lifetime: t.Lifetime,
+		})
+	}
+	if c.Rules.Level == "" {
+		return rules, nil
+	}
+	l, err := level.Load(c.Rules.Level)
+	if err != nil {
+		return rules, fmt.Errorf("rules.level: %w", err)
+	}
+	return rules.WithLevel(l), nil
+}
+
+// Board returns the board described by the rules
+func (c Config) Board() vars.Board {
+	return vars.Board{Width: c.Rules.Width, Height: c.Rules.Height, Wrap: c.Rules.Wrap}
+}
+
+// position returns the line and column of a byte offset in the data, both starting at 1
+func position(data []byte, offset int64) (line, column int) {
+	line, column = 1, 1
+	for _, b := range data[:min(int(offset), len(data))] {
+		if b == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"GoSnake/engine"
+	"GoSnake/food"
+)
+
+// write writes a configuration file in a temporary directory and returns its path
+func write(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), Path)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMissing(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("got %+v without a file, want the defaults", cfg)
+	}
+	rules, err := cfg.EngineRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rules, engine.DefaultRules()) {
+		t.Errorf("the default configuration plays with %+v, want the classic rules", rules)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := write(t, `{
+		"rules": {
+			"width": 20,
+			"wrap": true,
+			"start_interval_ms": 100,
+			"bonus": { "chance": 20, "lifetime": 30, "points": 5 },
+			"players": 2,
+			"separate_food": true,
+			"foods": [
+				{ "kind": "normal", "weight": 3, "points": 1, "grow": 1 },
+				{ "kind": "poison", "weight": 1, "deadly": true, "interval_ms": -5, "lifetime": 12 }
+			]
+		},
+		"visuals": { "tile_size": 16, "background": "#102030", "text": "#ffffff80" },
+		"controls": { "pause": ["P", "Space"] },
+		"player_two": { "up": ["I"] },
+		"audio": { "volume": 0.25 }
+	}`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Default()
+	want.Rules.Width = 20
+	want.Rules.Wrap = true
+	want.Rules.StartInterval = Milliseconds(100 * time.Millisecond)
+	want.Rules.Bonus = engine.BonusRules{Chance: 20, Lifetime: 30, Points: 5}
+	want.Rules.Players = 2
+	want.Rules.SeparateFood = true
+	want.Rules.Foods = []FoodType{
+		{Kind: "normal", Weight: 3, Points: 1, Grow: 1},
+		{Kind: "poison", Weight: 1, Deadly: true, Interval: Milliseconds(-5 * time.Millisecond), Lifetime: 12},
+	}
+	want.Visuals.TileSize = 16
+	want.Visuals.Background = Color{0x10, 0x20, 0x30, 255}
+	want.Visuals.Text = Color{255, 255, 255, 0x80}
+	want.Controls.Pause = []string{"P", "Space"}
+	want.PlayerTwo.Up = []string{"I"}
+	want.Audio.Volume = 0.25
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+
+	rules, err := cfg.EngineRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.Board.Width != 20 || rules.Board.Height != engine.DefaultRules().Board.Height || !rules.Board.Wrap {
+		t.Errorf("got the board %+v", rules.Board)
+	}
+	if rules.PlayerCount() != 2 || !rules.SeparateFood {
+		t.Errorf("got %d players with separate food %v, want 2 with their own food", rules.PlayerCount(), rules.SeparateFood)
+	}
+	if rules.StartInterval != 100*time.Millisecond {
+		t.Errorf("got a start interval of %v, want 100ms", rules.StartInterval)
+	}
+	if poison := rules.FoodType("poison"); poison != (food.Type{Kind: "poison", Weight: 1, Deadly: true, Interval: -5 * time.Millisecond, Lifetime: 12}) {
+		t.Errorf("got the poison %+v", poison)
+	}
+}
+
+func TestLoadLevel(t *testing.T) {
+	cfg, err := Load(write(t, `{"rules":{"width":10,"level":"../levels/box.json"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := cfg.EngineRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.Level == nil || rules.Board.Width != rules.Level.Width || rules.Board.Height != rules.Level.Height {
+		t.Errorf("got the board %+v and the level %+v, want the board to take the size of the level", rules.Board, rules.Level)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"syntax error", "{\n  \"rules\": {\n    \"width\": 20,\n  }\n}", "config.json:4:3:"},
+		{"misspelled setting", `{"rules":{"widht":20}}`, `unknown field "widht"`},
+		{"wrong type", `{"rules":{"width":"wide"}}`, "cannot unmarshal string"},
+		{"invalid color", `{"visuals":{"snake":"green"}}`, `expected a color like "#9ac600", got "green"`},
+		{"color not a string", `{"visuals":{"snake":255}}`, `expected a color like "#9ac600", got 255`},
+		{"invalid milliseconds", `{"rules":{"min_interval_ms":"fast"}}`, "expected a number of milliseconds"},
+		{"board too small", `{"rules":{"width":1}}`, "rules: the board must be at least 2x2 cells, got 1x48"},
+		{"start faster than the minimum", `{"rules":{"start_interval_ms":10,"min_interval_ms":20}}`, "rules: the start interval"},
+		{"food listed twice", `{"rules":{"foods":[{"kind":"a","weight":1},{"kind":"a","weight":1}]}}`, `rules: food type "a" is listed twice`},
+		{"bonus without points", `{"rules":{"bonus":{"chance":20,"lifetime":30}}}`, "rules: the bonus needs"},
+		{"too many portals", `{"rules":{"portals":1000}}`, "rules: the number of random portals"},
+		{"too many players", `{"rules":{"players":5}}`, "rules: the number of players must be between 1 and 4, got 5"},
+		{"no key for player two", `{"rules":{"players":2},"player_two":{"up":[]}}`, "player_two.up needs at least one key"},
+		{"missing level", `{"rules":{"level":"no/such/level.json"}}`, "rules.level: "},
+		{"no tiles", `{"visuals":{"tile_size":0}}`, "visuals.tile_size must be positive, got 0"},
+		{"no key", `{"controls":{"menu":[]}}`, "controls.menu needs at least one key"},
+		{"dead zone too large", `{"gamepad":{"dead_zone":1}}`, "gamepad.dead_zone must be at least 0 and less than 1"},
+		{"negative stick", `{"gamepad":{"stick_y":-1}}`, "must not be negative"},
+		{"negative button", `{"gamepad":{"start":[-2]}}`, "gamepad.start: invalid button -2"},
+		{"volume too loud", `{"audio":{"volume":1.5}}`, "audio.volume must be between 0 and 1, got 1.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(write(t, tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveControls(t *testing.T) {
+	path := write(t, `{"rules":{"width":30},"visuals":{"snake":"#000000"}}`)
+	controls := Default().Controls
+	controls.Up = []string{"I"}
+	if err := SaveControls(path, controls); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.Controls, controls) {
+		t.Errorf("got the controls %+v, want %+v", cfg.Controls, controls)
+	}
+	if cfg.Rules.Width != 30 || cfg.Visuals.Snake != (Color{0, 0, 0, 255}) {
+		t.Errorf("saving the controls lost the other settings: %+v", cfg)
+	}
+}
+
+func TestSaveControlsKeepsDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		data string // The file before saving the controls, empty for no file
+		want []string
+	}{
+		{"no file", "", []string{"controls"}},
+		{"only rules", `{"rules":{"width":30}}`, []string{"controls", "rules"}},
+		{"controls already saved", `{"rules":{"width":30},"controls":{"up":["W"]}}`, []string{"controls", "rules"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), Path)
+			if tt.data != "" {
+				path = write(t, tt.data)
+			}
+			if err := SaveControls(path, Default().Controls); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(data, &fields); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for name := range fields {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("the file holds %v after saving the controls, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveControlsInvalid(t *testing.T) {
+	data := `{"rules":{"width":1}}`
+	path := write(t, data)
+	if err := SaveControls(path, Default().Controls); err == nil {
+		t.Error("saved the controls in an invalid configuration")
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != data {
+		t.Errorf("the invalid configuration was overwritten with %s", got)
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"time"
+)
+
+// Milliseconds is a duration written as a number of milliseconds in the configuration
+type Milliseconds time.Duration
+
+// Duration returns the duration the milliseconds stand for
+func (m Milliseconds) Duration() time.Duration {
+	return time.Duration(m)
+}
+
+// MarshalJSON writes the duration as a number of milliseconds
+func (m Milliseconds) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(m) / float64(time.Millisecond))
+}
+
+// UnmarshalJSON reads a number of milliseconds
+func (m *Milliseconds) UnmarshalJSON(data []byte) error {
+	var ms float64
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return fmt.Errorf("expected a number of milliseconds, got %s", data)
+	}
+	*m = Milliseconds(ms * float64(time.Millisecond))
+	return nil
+}
+
+// Color is a color written as "#rrggbb" or "#rrggbbaa" in the configuration
+type Color struct {
+	R, G, B, A uint8
+}
+
+// ToRGBA returns the color as used by the renderer
+func (c Color) ToRGBA() color.RGBA {
+	return color.RGBA(c)
+}
+
+// MarshalJSON writes the color in hexadecimal
+func (c Color) MarshalJSON() ([]byte, error) {
+	if c.A == 255 {
+		return json.Marshal(fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+	}
+	return json.Marshal(fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A))
+}
+
+// UnmarshalJSON reads a color in hexadecimal
+func (c *Color) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("expected a color like \"#9ac600\", got %s", data)
+	}
+	parsed := Color{A: 255}
+	var n int
+	var err error
+	switch len(s) {
+	case 7:
+		n, err = fmt.Sscanf(s, "#%02x%02x%02x", &parsed.R, &parsed.G, &parsed.B)
+	case 9:
+		n, err = fmt.Sscanf(s, "#%02x%02x%02x%02x", &parsed.R, &parsed.G, &parsed.B, &parsed.A)
+	}
+	if err != nil || n < 3 {
+		return fmt.Errorf("expected a color like \"#9ac600\", got %q", s)
+	}
+	*c = parsed
+	return nil
+}
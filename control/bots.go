@@ -0,0 +1,22 @@
+package control
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Bots lists the names of the built-in bots, from the simplest to the one filling the board
+var Bots = []string{"greedy", "bfs", "hamiltonian"}
+
+// NewBot returns a new built-in bot by name
+func NewBot(name string) (Controller, error) {
+	switch name {
+	case "greedy":
+		return Greedy{}, nil
+	case "bfs":
+		return Pathfinder{}, nil
+	case "hamiltonian":
+		return &Hamiltonian{}, nil
+	}
+	return nil, fmt.Errorf("unknown bot %q, expected one of %s", name, strings.Join(Bots, ", "))
+}
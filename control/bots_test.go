@@ -0,0 +1,55 @@
+package control
+
+import (
+	"testing"
+
+	"GoSnake/engine"
+)
+
+func TestBotsNeverTurnBack(t *testing.T) {
+	rules := engine.DefaultRules()
+	rules.Board.Width, rules.Board.Height = 12, 10
+	versus := rules
+	versus.Players = 2
+	for _, name := range Bots {
+		for _, r := range []engine.Rules{rules, versus} {
+			for seed := int64(0); seed < 5; seed++ {
+				bots := make([]Controller, r.PlayerCount())
+				for i := range bots {
+					bot, err := NewBot(name)
+					if err != nil {
+						t.Fatal(err)
+					}
+					bots[i] = bot
+				}
+				state := engine.NewState(r, seed)
+				for !state.GameOver && !state.GameWon && state.Tick < 2000 {
+					var input engine.Input
+					for i, bot := range bots {
+						view := NewView(state, i)
+						direction := view.Direction()
+						for _, turn := range bot.Turns(view) {
+							if opposite(turn, direction) {
+								t.Fatalf("%s, %d players, seed %d: player %d turned %v at tick %d going %v", name, r.PlayerCount(), seed, i, turn, state.Tick, direction)
+							}
+							direction = turn
+							input.Add(i, turn)
+						}
+					}
+					state, _ = engine.Step(state, input)
+				}
+			}
+		}
+	}
+}
+
+func TestNewBot(t *testing.T) {
+	for _, name := range Bots {
+		if _, err := NewBot(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if _, err := NewBot("random"); err == nil {
+		t.Errorf("got no error for an unknown bot")
+	}
+}
@@ -0,0 +1,174 @@
+// Package control decides where the snakes go: whatever steers a snake, a player at the keyboard, a replay,
+// a client of a server or a bot, is a Controller asked for its turns before every move
+package control
+
+import (
+	"GoSnake/engine"
+	"GoSnake/vars"
+)
+
+// Directions lists the four directions a snake can take
+var Directions = []vars.Point{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}}
+
+// Controller steers a snake
+type Controller interface {
+	// Turns returns the directions to turn to before the next move, in order. Most controllers return
+	// at most one, and none to keep going straight
+	Turns(view View) []vars.Point
+}
+
+// View is a read-only view of a game from the side of one player, it's all a controller sees
+type View struct {
+	state  engine.State
+	player int
+}
+
+// NewView returns the view of a game for a player
+func NewView(state engine.State, player int) View {
+	return View{state: state, player: player}
+}
+
+// Player returns the player the view is for
+func (v View) Player() int {
+	return v.player
+}
+
+// Players returns the number of snakes on the board
+func (v View) Players() int {
+	return len(v.state.Snakes)
+}
+
+// Tick returns the number of moves made since the start of the game
+func (v View) Tick() int {
+	return v.state.Tick
+}
+
+// Board returns the board the game is played on
+func (v View) Board() vars.Board {
+	return v.state.Rules.Board
+}
+
+// Head returns the head of the player's snake
+func (v View) Head() vars.Point {
+	return v.state.Snakes[v.player].Body[0]
+}
+
+// Body returns the body of a snake, head first
+func (v View) Body(player int) []vars.Point {
+	return append([]vars.Point(nil), v.state.Snakes[player].Body...)
+}
+
+// Direction returns the direction the player's snake takes on its next move if it isn't turned again,
+// the last turn it has queued or the direction it's going in
+func (v View) Direction() vars.Point {
+	snake := v.state.Snakes[v.player]
+	if len(snake.Turns) > 0 {
+		return snake.Turns[len(snake.Turns)-1]
+	}
+	return snake.Direction
+}
+
+// Score returns the score of a player
+func (v View) Score(player int) int {
+	return v.state.Scores[player]
+}
+
+// Targets returns the cells worth going to: the food the player can eat, unless it does more harm than good,
+// and the bonus food while it's there
+func (v View) Targets() []vars.Point {
+	var targets []vars.Point
+	if f := v.state.Foods[v.state.FoodOf(v.player)]; v.worthEating(f.Kind) {
+		targets = append(targets, f.Position)
+	}
+	if v.state.Bonus.Active {
+		targets = append(targets, v.state.Bonus.Position)
+	}
+	return targets
+}
+
+// Next returns the cell a snake enters when it moves from a cell in a direction, through the portal it
+// may step on. The cell may be off the board
+func (v View) Next(p, direction vars.Point) vars.Point {
+	n := v.state.Rules.Board.Neighbor(p, direction)
+	for _, portal := range v.state.Portals {
+		if exit, ok := portal.Exit(n); ok {
+			return exit
+		}
+	}
+	return n
+}
+
+// Blocked reports whether a snake entering a cell on the next move dies there
+func (v View) Blocked(p vars.Point) bool {
+	return v.BlockedAt(p, 1)
+}
+
+// BlockedAt reports whether a snake entering a cell in a number of moves dies there: the cell is off the board,
+// a wall or a deadly food, or a snake's tail hasn't left it yet
+func (v View) BlockedAt(p vars.Point, moves int) bool {
+	return v.Wall(p) || v.Deadly(p) || v.FreeAt(p) > moves
+}
+
+// Wall reports whether a cell is off the board or a wall of the level
+func (v View) Wall(p vars.Point) bool {
+	if !v.state.Rules.Board.Contains(p) {
+		return true
+	}
+	return v.state.Rules.Level != nil && v.state.Rules.Level.IsWall(p)
+}
+
+// Deadly reports whether a cell holds a food killing the snake eating it
+func (v View) Deadly(p vars.Point) bool {
+	for _, f := range v.state.Foods {
+		if f.Position == p && v.state.Rules.FoodType(f.Kind).Deadly {
+			return true
+		}
+	}
+	return false
+}
+
+// Portal reports whether a cell is an end of a portal
+func (v View) Portal(p vars.Point) bool {
+	for _, portal := range v.state.Portals {
+		if _, ok := portal.Exit(p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FreeAt returns the number of moves after which a cell taken by a snake is left by its tail, assuming the snakes
+// don't eat in the meantime. A snake can enter the cell on that move, 0 means the cell is free
+func (v View) FreeAt(p vars.Point) int {
+	free := 0
+	for _, snake := range v.state.Snakes {
+		if snake.Dead {
+			continue
+		}
+		for i, part := range snake.Body {
+			if part == p {
+				free = max(free, len(snake.Body)-i+snake.GrowCounter)
+			}
+		}
+	}
+	return free
+}
+
+// worthEating reports whether a kind of food is good for the snake
+func (v View) worthEating(kind string) bool {
+	t := v.state.Rules.FoodType(kind)
+	return !t.Deadly && t.Points >= 0
+}
+
+// turn returns the turns taking a snake in a direction, none if it's already going that way
+func turn(view View, direction vars.Point) []vars.Point {
+	if direction == view.Direction() {
+		return nil
+	}
+	return []vars.Point{direction}
+}
+
+// opposite reports whether two directions are opposite, a snake can't turn back on itself
+func opposite(a, b vars.Point) bool {
+	return a.X == -b.X && a.Y == -b.Y
+}
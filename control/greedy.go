@@ -0,0 +1,35 @@
+package control
+
+import (
+	"GoSnake/vars"
+)
+
+// Greedy heads for the nearest target, only avoiding the cells that kill it on the next move.
+// It's easily trapped by its own body once it gets long
+type Greedy struct{}
+
+// Turns takes the safe direction getting closest to a target, going straight when it's as good
+func (Greedy) Turns(view View) []vars.Point {
+	best, bestDistance := view.Direction(), -1
+	for _, direction := range append([]vars.Point{view.Direction()}, Directions...) {
+		next := view.Next(view.Head(), direction)
+		if opposite(direction, view.Direction()) || view.Blocked(next) {
+			continue
+		}
+		if distance := nearest(view, next); bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = direction, distance
+		}
+	}
+	return turn(view, best)
+}
+
+// nearest returns the distance from a cell to the nearest target, 0 if there's none
+func nearest(view View, p vars.Point) int {
+	distance := 0
+	for i, target := range view.Targets() {
+		if d := view.Board().Distance(p, target); i == 0 || d < distance {
+			distance = d
+		}
+	}
+	return distance
+}
@@ -0,0 +1,82 @@
+package control
+
+import (
+	"GoSnake/vars"
+)
+
+// Hamiltonian follows a cycle going through every cell of the board, so it never runs into itself and can fill
+// the whole board, slowly. The cycle exists when the width or the height of the board is even, and is only
+// followed when no wall or portal is on its way. Otherwise, or when another snake or a deadly food is in
+// its way, the Pathfinder takes over
+type Hamiltonian struct {
+	board    vars.Board
+	next     map[vars.Point]vars.Point // The cell after each cell on the cycle, nil when the board has none to follow
+	fallback Pathfinder
+}
+
+// Turns heads for the next cell on the cycle
+func (h *Hamiltonian) Turns(view View) []vars.Point {
+	if h.next == nil || view.Board() != h.board || view.Tick() == 0 {
+		h.board = view.Board()
+		h.next = cycle(h.board)
+		for p := range h.next {
+			if view.Wall(p) || view.Portal(p) {
+				h.next = nil // Broken for good, following it would go round in circles
+				break
+			}
+		}
+	}
+
+	head := view.Head()
+	next, ok := h.next[head]
+	if !ok {
+		return h.fallback.Turns(view)
+	}
+	for _, direction := range Directions {
+		if view.Board().Neighbor(head, direction) != next {
+			continue
+		}
+		if opposite(direction, view.Direction()) || view.Next(head, direction) != next || view.Blocked(next) {
+			break
+		}
+		return turn(view, direction)
+	}
+	return h.fallback.Turns(view)
+}
+
+// cycle returns a cycle going through every cell of a board, as the cell after each cell, or nil when there's none.
+// It goes along the rows one way and the other, skipping the first column which leads back to the start
+func cycle(board vars.Board) map[vars.Point]vars.Point {
+	width, height := board.Width, board.Height
+	transpose := height%2 != 0
+	if transpose {
+		width, height = height, width
+	}
+	if height%2 != 0 || width < 2 {
+		return nil
+	}
+
+	var order []vars.Point
+	for y := 0; y < height; y++ {
+		for i := 1; i < width; i++ {
+			x := i
+			if y%2 != 0 {
+				x = width - i
+			}
+			order = append(order, vars.Point{X: x, Y: y})
+		}
+	}
+	for y := height - 1; y >= 0; y-- {
+		order = append(order, vars.Point{X: 0, Y: y})
+	}
+
+	next := make(map[vars.Point]vars.Point, len(order))
+	for i, p := range order {
+		q := order[(i+1)%len(order)]
+		if transpose {
+			p, q = vars.Point{X: p.Y, Y: p.X}, vars.Point{X: q.Y, Y: q.X}
+		}
+		next[p] = q
+	}
+	return next
+}
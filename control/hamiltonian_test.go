@@ -0,0 +1,51 @@
+package control
+
+import (
+	"testing"
+
+	"GoSnake/vars"
+)
+
+func TestCycle(t *testing.T) {
+	tests := []struct {
+		width, height int
+		ok            bool // Whether the board has a cycle
+	}{
+		{2, 2, true},
+		{4, 4, true},
+		{6, 3, true},
+		{3, 6, true},
+		{5, 4, true},
+		{7, 2, true},
+		{64, 48, true},
+		{3, 3, false},
+		{5, 7, false},
+	}
+	for _, tt := range tests {
+		board := vars.Board{Width: tt.width, Height: tt.height}
+		next := cycle(board)
+		if (next != nil) != tt.ok {
+			t.Errorf("%dx%d: got a cycle %v, want one %v", tt.width, tt.height, next != nil, tt.ok)
+			continue
+		}
+		if next == nil {
+			continue
+		}
+
+		// Following the cycle from any cell goes through every cell once, one step at a time, and back
+		cells := tt.width * tt.height
+		seen := map[vars.Point]bool{}
+		p := vars.Point{}
+		for i := 0; i < cells; i++ {
+			q, ok := next[p]
+			if seen[p] || !ok || !board.Contains(q) || board.Distance(p, q) != 1 {
+				t.Fatalf("%dx%d: step %d goes from %v to %v, visited before %v", tt.width, tt.height, i, p, q, seen[p])
+			}
+			seen[p] = true
+			p = q
+		}
+		if p != (vars.Point{}) || len(next) != cells {
+			t.Errorf("%dx%d: the cycle ends on %v after going through %d cells, want %d ending on the start", tt.width, tt.height, p, len(seen), cells)
+		}
+	}
+}
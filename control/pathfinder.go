@@ -0,0 +1,143 @@
+package control
+
+import (
+	"GoSnake/vars"
+)
+
+// Pathfinder follows the shortest path to a target, found with a breadth-first search knowing when the
+// tails of the snakes leave their cells. It only takes a path leaving it room to move once it gets
+// there, and otherwise heads where it has the most room
+type Pathfinder struct{}
+
+// Turns takes the first step of the shortest safe path, or of the way with the most room
+func (Pathfinder) Turns(view View) []vars.Point {
+	if path, first := shortestPath(view); path != nil && roomAfter(view, path) {
+		return turn(view, first)
+	}
+	return turn(view, roomiest(view))
+}
+
+// shortestPath returns the cells on the shortest way to a target, the head excluded, and the first direction
+// to take. The path is nil when no target can be reached
+func shortestPath(view View) ([]vars.Point, vars.Point) {
+	targets := view.Targets()
+	if len(targets) == 0 {
+		return nil, vars.Point{}
+	}
+
+	// Search by number of moves, so a cell taken by a snake can be entered once its tail has left it
+	type node struct {
+		cell  vars.Point
+		moves int
+		first vars.Point // The direction taken from the head to get there
+	}
+	head := view.Head()
+	from := map[vars.Point]vars.Point{head: head}
+	queue := []node{{cell: head}}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, direction := range Directions {
+			if n.moves == 0 && opposite(direction, view.Direction()) {
+				continue
+			}
+			next := view.Next(n.cell, direction)
+			if _, seen := from[next]; seen || view.BlockedAt(next, n.moves+1) {
+				continue
+			}
+			from[next] = n.cell
+			first := n.first
+			if n.moves == 0 {
+				first = direction
+			}
+			if contains(targets, next) {
+				var path []vars.Point
+				for p := next; p != head; p = from[p] {
+					path = append([]vars.Point{p}, path...)
+				}
+				return path, first
+			}
+			queue = append(queue, node{cell: next, moves: n.moves + 1, first: first})
+		}
+	}
+	return nil, vars.Point{}
+}
+
+// roomAfter reports whether the snake still has room to move after following a path and eating at its end:
+// it can reach its tail or at least as many cells as it will be long
+func roomAfter(view View, path []vars.Point) bool {
+	// The snake after the path, one segment longer for the food
+	body := view.Body(view.Player())
+	for _, p := range path {
+		body = append([]vars.Point{p}, body...)
+	}
+	body = body[:min(len(body), len(view.Body(view.Player()))+1)]
+	tail := body[len(body)-1]
+
+	taken := map[vars.Point]bool{}
+	for _, p := range body[:len(body)-1] {
+		taken[p] = true
+	}
+	for player := 0; player < view.Players(); player++ {
+		if player != view.Player() {
+			for _, p := range view.Body(player) {
+				taken[p] = true
+			}
+		}
+	}
+	n, reached := room(view, body[0], func(p vars.Point) bool { return taken[p] }, tail, len(body))
+	return reached || n >= len(body)
+}
+
+// roomiest returns the safe direction leading to the most cells, the one closest to a target among them,
+// or the current direction when every way is deadly
+func roomiest(view View) vars.Point {
+	best, bestRoom, bestDistance := view.Direction(), -1, 0
+	limit := view.Board().Width * view.Board().Height
+	for _, direction := range append([]vars.Point{view.Direction()}, Directions...) {
+		next := view.Next(view.Head(), direction)
+		if opposite(direction, view.Direction()) || view.Blocked(next) {
+			continue
+		}
+		n, _ := room(view, next, func(p vars.Point) bool { return view.FreeAt(p) > 1 }, next, limit)
+		distance := nearest(view, next)
+		if n > bestRoom || (n == bestRoom && distance < bestDistance) {
+			best, bestRoom, bestDistance = direction, n, distance
+		}
+	}
+	return best
+}
+
+// room counts the cells reachable from a cell without going through walls or taken cells, stopping at a limit,
+// and reports whether a goal cell was reached on the way
+func room(view View, start vars.Point, taken func(vars.Point) bool, goal vars.Point, limit int) (int, bool) {
+	seen := map[vars.Point]bool{start: true}
+	stack := []vars.Point{start}
+	reached := false
+	for len(stack) > 0 && len(seen) < limit {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, direction := range Directions {
+			next := view.Next(p, direction)
+			if next == goal {
+				reached = true
+			}
+			if seen[next] || view.Wall(next) || view.Deadly(next) || taken(next) {
+				continue
+			}
+			seen[next] = true
+			stack = append(stack, next)
+		}
+	}
+	return len(seen), reached
+}
+
+// contains reports whether a list of cells holds a cell
+func contains(cells []vars.Point, p vars.Point) bool {
+	for _, c := range cells {
+		if c == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package control
+
+import (
+	"reflect"
+	"testing"
+
+	"GoSnake/engine"
+	"GoSnake/food"
+	"GoSnake/level"
+	"GoSnake/vars"
+)
+
+var (
+	up    = vars.Point{X: 0, Y: -1}
+	down  = vars.Point{X: 0, Y: 1}
+	left  = vars.Point{X: -1, Y: 0}
+	right = vars.Point{X: 1, Y: 0}
+)
+
+// game returns a game on a board with a single snake, head first, and its food on a cell
+func game(rules engine.Rules, body []vars.Point, direction, f vars.Point) engine.State {
+	state := engine.NewState(rules, 1)
+	state.Snakes[0] = engine.Snake{Body: body, Direction: direction}
+	state.Foods[0] = food.Food{Position: f, Kind: food.Normal}
+	state.Portals = nil
+	return state
+}
+
+func TestShortestPath(t *testing.T) {
+	// A wall down the middle of the board, open at the bottom
+	walled := level.New("walled", 7, 7)
+	for y := 0; y < 5; y++ {
+		walled.Set(vars.Point{X: 3, Y: y}, level.Wall)
+	}
+	walled.Set(vars.Point{X: 1, Y: 2}, level.Spawn)
+	if err := walled.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	open := engine.DefaultRules()
+	open.Board.Width, open.Board.Height = 10, 10
+
+	tests := []struct {
+		name   string
+		state  engine.State
+		length int          // The number of moves to the food
+		first  []vars.Point // The directions the path may start with
+	}{
+		{"straight", game(open, []vars.Point{{X: 2, Y: 5}}, right, vars.Point{X: 6, Y: 5}), 4, []vars.Point{right}},
+		{"around a wall", game(open.WithLevel(walled), []vars.Point{{X: 1, Y: 2}}, right, vars.Point{X: 5, Y: 2}), 10, []vars.Point{right, down}},
+		{"behind its body", game(open, []vars.Point{{X: 4, Y: 5}, {X: 5, Y: 5}, {X: 6, Y: 5}, {X: 7, Y: 5}}, left, vars.Point{X: 8, Y: 5}), 6, []vars.Point{up, down}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := NewView(tt.state, 0)
+			path, first := shortestPath(view)
+			if len(path) != tt.length || path[len(path)-1] != tt.state.Foods[0].Position || !contains(tt.first, first) {
+				t.Fatalf("got the path %v starting %v, want %d moves to the food starting one of %v", path, first, tt.length, tt.first)
+			}
+			p := view.Head()
+			for i, q := range path {
+				if view.Board().Distance(p, q) != 1 || view.Wall(q) || view.FreeAt(q) > i+1 {
+					t.Fatalf("move %d goes from %v to %v, through a wall or a snake", i+1, p, q)
+				}
+				p = q
+			}
+			if turns, want := (Pathfinder{}).Turns(view), turn(view, first); !reflect.DeepEqual(turns, want) {
+				t.Errorf("the pathfinder turns %v, want %v", turns, want)
+			}
+		})
+	}
+
+	// Walled in by its own body, there's no way to the food
+	state := game(open, []vars.Point{{X: 1, Y: 1}, {X: 1, Y: 0}, {X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 2, Y: 0}}, down, vars.Point{X: 8, Y: 8})
+	if path, _ := shortestPath(NewView(state, 0)); path != nil {
+		t.Errorf("got the path %v out of the body", path)
+	}
+}
@@ -0,0 +1,64 @@
+package control
+
+import (
+	"GoSnake/engine"
+	"GoSnake/replay"
+	"GoSnake/vars"
+)
+
+// replayInput reads the input of a replay once per tick for the controllers of every player
+type replayInput struct {
+	player *replay.Player
+	tick   int          // The tick the input was read at, -1 before the first one
+	input  engine.Input // The input of every player at that tick
+}
+
+// replayController plays the turns a player recorded in a replay
+type replayController struct {
+	input  *replayInput
+	player int
+}
+
+// Replay returns controllers playing the turns recorded by each player of a replay. The replay player must
+// be at the start of the replay and isn't used anywhere else while the controllers play it
+func Replay(p *replay.Player, players int) []Controller {
+	input := &replayInput{player: p, tick: -1}
+	controllers := make([]Controller, players)
+	for i := range controllers {
+		controllers[i] = &replayController{input: input, player: i}
+	}
+	return controllers
+}
+
+// Turns returns the turns recorded by the player before the move
+func (c *replayController) Turns(view View) []vars.Point {
+	if view.Tick() != c.input.tick {
+		c.input.tick = view.Tick()
+		c.input.input = c.input.player.Input(view.Tick())
+	}
+	if c.player < len(c.input.input.Turns) {
+		return c.input.input.Turns[c.player]
+	}
+	return nil
+}
+
+// perMove asks a controller for its turns only once per move
+type perMove struct {
+	controller Controller
+	tick       int // The tick the controller was last asked at, -1 before it's asked
+}
+
+// PerMove returns a controller asking another one for its turns only once per move, for controllers asked
+// more often than the snake moves, such as on every frame, whose turns would pile up otherwise
+func PerMove(c Controller) Controller {
+	return &perMove{controller: c, tick: -1}
+}
+
+// Turns returns the turns of the controller the first time it's asked for a move, none afterwards
+func (c *perMove) Turns(view View) []vars.Point {
+	if view.Tick() == c.tick {
+		return nil
+	}
+	c.tick = view.Tick()
+	return c.controller.Turns(view)
+}
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"GoSnake/event"
+	"GoSnake/food"
+)
+
+// BonusRules are the settings of the bonus food, which appears once in a while next to the regular food
+type BonusRules struct {
+	Chance   int `json:"chance"`   // The percentage of chance a bonus appears when the food is eaten, 0 for no bonus
+	Lifetime int `json:"lifetime"` // The number of moves the bonus stays on the board
+	Points   int `json:"points"`   // The points the bonus is worth when it's eaten right away
+}
+
+// Enabled reports whether bonuses appear at all
+func (b BonusRules) Enabled() bool {
+	return b.Chance > 0
+}
+
+// maybeSpawnBonus puts a bonus on the board once in a while, the random source is untouched when bonuses are off
+// so games without them play the same as before they existed. There's no bonus when no cell is free for it
+func (s *State) maybeSpawnBonus() {
+	if !s.Rules.Bonus.Enabled() || s.Bonus.Active {
+		return
+	}
+	if s.RNG.Intn(100) >= s.Rules.Bonus.Chance {
+		return
+	}
+	var spot food.Food
+	if !spot.ResetWhere(&s.RNG, s.Rules.Board, s.allowsFood) {
+		return // The food covers every cell it may appear on
+	}
+	s.Bonus.Position = spot.Position
+	s.Bonus.Spawned = s.Tick
+	s.Bonus.Expires = s.Tick + s.Rules.Bonus.Lifetime
+	s.Bonus.Active = true
+}
+
+// checkBonus awards the bonus to the snake reaching it and removes it once it has expired
+func (s *State) checkBonus() []event.Event {
+	if !s.Bonus.Active {
+		return nil
+	}
+	for i, snake := range s.Snakes {
+		head := snake.Body[0]
+		if snake.Dead || head != s.Bonus.Position {
+			continue
+		}
+		points := s.Bonus.Points(s.Tick, s.Rules.Bonus.Points)
+		s.Scores[i] += points
+		s.Bonus.Active = false
+		events := []event.Event{event.BonusEaten{Position: head, Points: points, Score: s.Scores[i], Player: i}}
+		if s.Scores[i] >= s.Rules.WinScore {
+			s.GameWon = true
+			s.Winner = i
+			events = append(events, event.GameWon{Score: s.Scores[i], Player: i})
+		}
+		return events
+	}
+	if s.Tick >= s.Bonus.Expires {
+		s.Bonus.Active = false
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package engine
+
+import (
+	"GoSnake/event"
+	"GoSnake/food"
+	"GoSnake/vars"
+)
+
+// checkCollisions checks for collisions between the snakes and the food, the game boundaries or each other.
+// All the snakes have moved when it's called, so snakes running into each other die together
+func (s *State) checkCollisions() []event.Event {
+	var events []event.Event
+	for i := range s.Snakes {
+		if s.Snakes[i].Dead {
+			continue
+		}
+		if cause, dead := s.collision(i); dead {
+			head := s.Snakes[i].Body[0]
+			events = append(events, event.SnakeDied{Cause: cause, Position: head, Score: s.Scores[i], Player: i})
+		}
+	}
+	if len(events) > 0 {
+		s.end(events)
+		if s.GameOver {
+			return events
+		}
+	}
+
+	// Check for collision with food
+	for i := range s.Snakes {
+		if s.Snakes[i].Dead {
+			continue
+		}
+		if f := s.foodAt(i); f >= 0 {
+			events = append(events, s.eat(i, f)...)
+			if s.GameOver || s.GameWon {
+				return events
+			}
+		}
+	}
+	return events
+}
+
+// collision reports whether a snake hit something deadly and what
+func (s *State) collision(i int) (event.DeathCause, bool) {
+	snake := s.Snakes[i]
+	head := snake.Body[0]
+
+	// Check for collision with game boundaries, the head never leaves a wrapping board
+	if !s.Rules.Board.Contains(head) {
+		return event.HitWall, true
+	}
+
+	// Check for collision with the walls of the level
+	if s.Rules.Level != nil && s.Rules.Level.IsWall(head) {
+		return event.HitWall, true
+	}
+
+	// Check for self-collisions
+	for _, part := range snake.Body[1:] {
+		if head.X == part.X && head.Y == part.Y {
+			return event.HitSelf, true
+		}
+	}
+
+	// Check for collisions with the other snakes: running into their body, meeting their head on the same cell,
+	// or swapping cells with it when they come face to face
+	for j, other := range s.Snakes {
+		if j == i || other.Dead {
+			continue
+		}
+		if head == other.Body[0] || (len(snake.Body) > 1 && len(other.Body) > 1 && head == other.Body[1] && other.Body[0] == snake.Body[1]) {
+			return event.HitSnake, true
+		}
+		for _, part := range other.Body[1:] {
+			if head == part {
+				return event.HitSnake, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// eat applies the effects of a food to the snake of a player and puts a new food on the board
+func (s *State) eat(i, f int) []event.Event {
+	head := s.Snakes[i].Body[0]
+	eaten := s.Rules.FoodType(s.Foods[f].Kind)
+	s.Scores[i] = max(s.Scores[i]+eaten.Points, 0)
+	if eaten.Grow >= 0 {
+		s.Snakes[i].GrowCounter += eaten.Grow
+	} else {
+		s.Snakes[i].Shrink(-eaten.Grow)
+	}
+	if f < s.Rules.FoodSlots() {
+		s.placeFood(f)
+		s.maybeSpawnBonus()
+	} else {
+		s.Foods = append(s.Foods[:f], s.Foods[f+1:]...)
+	}
+	events := []event.Event{event.FoodEaten{Position: head, Score: s.Scores[i], Kind: eaten.Kind, Player: i}}
+
+	// Check if the food was poisoned
+	if eaten.Deadly {
+		died := event.SnakeDied{Cause: event.Poisoned, Position: head, Score: s.Scores[i], Player: i}
+		s.end([]event.Event{died})
+		return append(events, died)
+	}
+
+	// Check if the player has won the game
+	if s.Scores[i] >= s.Rules.WinScore {
+		s.GameWon = true
+		s.Winner = i
+		return append(events, event.GameWon{Score: s.Scores[i], Player: i})
+	}
+
+	// Change the speed of the snakes, they never get slower than at the start nor faster than the shortest interval
+	s.MoveInterval += eaten.Interval
+	s.MoveInterval = min(max(s.MoveInterval, s.Rules.MinInterval), max(s.Rules.StartInterval, s.Rules.MinInterval))
+	return events
+}
+
+// end takes the snakes that died off the board. The game is over once at most one snake is left, the last
+// snake alive wins a game between several players and it's a draw when the last ones die together
+func (s *State) end(deaths []event.Event) {
+	for _, e := range deaths {
+		s.Snakes[e.(event.SnakeDied).Player].Dead = true
+	}
+	alive := -1
+	for i, snake := range s.Snakes {
+		if snake.Dead {
+			continue
+		}
+		if alive >= 0 {
+			return // Several snakes play on
+		}
+		alive = i
+	}
+	s.GameOver = true
+	if len(s.Snakes) > 1 {
+		s.Winner = alive
+	}
+}
+
+// FoodOf returns the index of the food a player looks for
+func (s *State) FoodOf(player int) int {
+	if s.Rules.SeparateFood {
+		return player
+	}
+	return 0
+}
+
+// foodAt returns the index of the food under the head of a player's snake, -1 if there's none it can eat.
+// Deadly food is eaten by whoever runs into it
+func (s *State) foodAt(player int) int {
+	head := s.Snakes[player].Body[0]
+	if f := s.FoodOf(player); head == s.Foods[f].Position {
+		return f
+	}
+	for f := s.Rules.FoodSlots(); f < len(s.Foods); f++ {
+		if head == s.Foods[f].Position {
+			return f
+		}
+	}
+	return -1
+}
+
+// allowsFood reports whether food may appear on a cell, which must not be a wall, a cell the level keeps
+// free of food, a portal, another food or the bonus
+func (s *State) allowsFood(p vars.Point) bool {
+	if s.Rules.Level != nil && !s.Rules.Level.AllowsFood(p) {
+		return false
+	}
+	if s.Bonus.Active && s.Bonus.Position == p {
+		return false
+	}
+	for _, f := range s.Foods {
+		if f.Position == p {
+			return false
+		}
+	}
+	return portalAt(s.Portals, p) < 0
+}
+
+// placeFood moves a food to a random cell, avoiding the cells the level keeps free of food, and picks its kind.
+// When a deadly kind is picked it appears next to the food instead of replacing it, so there's always something
+// to eat, and it goes away after a while
+func (s *State) placeFood(i int) {
+	s.Foods[i].Position = vars.Point{X: -1, Y: -1} // Off the board so it doesn't stand in its own way
+	f := food.Food{Position: s.Foods[i].Position}
+	f.ResetWhere(&s.RNG, s.Rules.Board, s.allowsFood) // Validate makes sure every food has a cell
+	kind := food.Pick(&s.RNG, s.Rules.FoodTypes())
+	if kind.Deadly {
+		s.Foods[i] = f
+		s.spawnDeadly(kind)
+		kind = food.Pick(&s.RNG, s.Rules.edibleFoodTypes())
+	}
+	f.Kind = kind.Kind
+	s.Foods[i] = f
+}
+
+// spawnDeadly puts a deadly food on the board for its lifetime, unless no cell is free for it
+func (s *State) spawnDeadly(t food.Type) {
+	f := food.Food{Position: vars.Point{X: -1, Y: -1}, Kind: t.Kind, Expires: s.Tick + s.Rules.deadlyLifetime(t)}
+	if f.ResetWhere(&s.RNG, s.Rules.Board, s.allowsFood) {
+		s.Foods = append(s.Foods, f)
+	}
+}
+
+// expireFoods removes the deadly food whose time is up
+func (s *State) expireFoods() {
+	foods := s.Foods[:s.Rules.FoodSlots()]
+	for _, f := range s.Foods[len(foods):] {
+		if s.Tick < f.Expires {
+			foods = append(foods, f)
+		}
+	}
+	s.Foods = foods
+}
@@ -0,0 +1,284 @@
+// Package engine implements the rules of the game without depending on
+// ebiten, so games can be simulated without a window
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"GoSnake/event"
+	"GoSnake/food"
+	"GoSnake/level"
+	"GoSnake/rng"
+	"GoSnake/vars"
+)
+
+// Rules are the settings that change how a game plays
+type Rules struct {
+	Board         vars.Board    `json:"board"`           // The board the game is played on
+	StartInterval time.Duration `json:"start_interval"`  // The time between two moves at the start of a game
+	MinInterval   time.Duration `json:"min_interval"`    // The shortest time between two moves
+	IntervalStep  time.Duration `json:"interval_step"`   // How much faster the snake gets each time it eats
+	WinScore      int           `json:"win_score"`       // The score needed to win the game
+	Level         *level.Level  `json:"level,omitempty"` // The arena the game is played in, nil for an empty board
+	Foods         []food.Type   `json:"foods,omitempty"` // The kinds of food that can appear, nil for the classic food only
+	Bonus         BonusRules    `json:"bonus"`           // When the bonus food appears and what it's worth
+	Portals       int           `json:"portals"`         // The number of portals placed at random, on top of those of the level
+	Players       int           `json:"players"`         // The number of snakes on the board, 0 means 1
+	SeparateFood  bool          `json:"separate_food"`   // Whether each player has their own food instead of sharing one
+}
+
+// DefaultRules returns the rules of the classic game
+func DefaultRules() Rules {
+	return Rules{
+		Board:         vars.DefaultBoard,
+		StartInterval: 10 * time.Second / 60,
+		MinInterval:   2 * time.Second / 60,
+		IntervalStep:  time.Second / 60,
+		WinScore:      25,
+	}
+}
+
+// FoodTypes returns the kinds of food that can appear, the classic food gives a point, a segment and speeds
+// the snake up by the interval step
+func (r Rules) FoodTypes() []food.Type {
+	if len(r.Foods) > 0 {
+		return r.Foods
+	}
+	return []food.Type{{Kind: food.Normal, Weight: 1, Points: 1, Grow: 1, Interval: -r.IntervalStep}}
+}
+
+// edibleFoodTypes returns the kinds of food that don't kill the snake, the ones the snakes look for
+func (r Rules) edibleFoodTypes() []food.Type {
+	var types []food.Type
+	for _, t := range r.FoodTypes() {
+		if !t.Deadly {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// deadlyLifetime returns the number of moves a deadly food stays on the board, by default as many as it
+// takes to cross the board both ways
+func (r Rules) deadlyLifetime(t food.Type) int {
+	if t.Lifetime > 0 {
+		return t.Lifetime
+	}
+	return r.Board.Width + r.Board.Height
+}
+
+// FoodType returns the type of a kind of food, the first type if the kind is unknown
+func (r Rules) FoodType(kind string) food.Type {
+	types := r.FoodTypes()
+	for _, t := range types {
+		if t.Kind == kind {
+			return t
+		}
+	}
+	return types[0]
+}
+
+// PlayerCount returns the number of snakes on the board
+func (r Rules) PlayerCount() int {
+	return max(r.Players, 1)
+}
+
+// FoodSlots returns the number of foods the snakes look for, one for all of them or one for each
+func (r Rules) FoodSlots() int {
+	if r.SeparateFood {
+		return r.PlayerCount()
+	}
+	return 1
+}
+
+// WithLevel returns the rules played in a level, the board takes the size of the level and wraps if either says so
+func (r Rules) WithLevel(l *level.Level) Rules {
+	r.Level = l
+	r.Board.Width, r.Board.Height = l.Width, l.Height
+	r.Board.Wrap = r.Board.Wrap || l.Wrap
+	return r
+}
+
+// Validate checks the rules make a playable game
+func (r Rules) Validate() error {
+	if r.Level != nil {
+		if err := r.Level.Validate(); err != nil {
+			return fmt.Errorf("level %q: %w", r.Level.Name, err)
+		}
+		if r.Level.Width != r.Board.Width || r.Level.Height != r.Board.Height {
+			return fmt.Errorf("level %q is %dx%d cells but the board is %dx%d", r.Level.Name, r.Level.Width, r.Level.Height, r.Board.Width, r.Board.Height)
+		}
+	}
+	switch {
+	case r.Board.Width < 2 || r.Board.Height < 2:
+		return fmt.Errorf("the board must be at least 2x2 cells, got %dx%d", r.Board.Width, r.Board.Height)
+	case r.MinInterval <= 0:
+		return fmt.Errorf("the minimum interval must be positive, got %v", r.MinInterval)
+	case r.StartInterval < r.MinInterval:
+		return fmt.Errorf("the start interval (%v) must not be shorter than the minimum interval (%v)", r.StartInterval, r.MinInterval)
+	case r.IntervalStep < 0:
+		return fmt.Errorf("the interval step must not be negative, got %v", r.IntervalStep)
+	case r.WinScore <= 0:
+		return fmt.Errorf("the winning score must be positive, got %d", r.WinScore)
+	}
+	if r.Players < 0 || r.Players > MaxPlayers {
+		return fmt.Errorf("the number of players must be between 1 and %d, got %d", MaxPlayers, r.Players)
+	}
+	if r.Level != nil && r.PlayerCount() > 2 {
+		return fmt.Errorf("levels have spawns for 2 players, got %d", r.Players)
+	}
+	if r.Level != nil && r.PlayerCount() == 2 {
+		second := NewSnakes(r)[1]
+		switch {
+		case second.Body[0] == r.Level.Spawn:
+			return fmt.Errorf("level %q: the spawn is in the middle of the board, the second snake would start on the first one", r.Level.Name)
+		case r.Level.IsWall(second.Body[0]) || r.Level.IsWall(r.Board.Neighbor(second.Body[0], second.Direction)):
+			return fmt.Errorf("level %q: the second snake, across from the first one, would start in a wall", r.Level.Name)
+		}
+	}
+	if r.Portals < 0 || 2*r.Portals > r.Board.Width*r.Board.Height/4 {
+		return fmt.Errorf("the number of random portals must be between 0 and an eighth of the cells, got %d", r.Portals)
+	}
+	if r.Portals > 0 {
+		var portals []level.Portal
+		if r.Level != nil {
+			portals = r.Level.Portals
+		}
+		if cells := countCells(r.Board, portalAllowed(r, &portals)); 2*r.Portals > cells {
+			return fmt.Errorf("%d random portals need %d free cells, only %d are left", r.Portals, 2*r.Portals, cells)
+		}
+	}
+	if cells := r.foodCells() - 2*r.Portals; cells < r.FoodSlots() {
+		return fmt.Errorf("%d foods don't fit in the %d cells left for food once the random portals are placed", r.FoodSlots(), max(cells, 0))
+	}
+	if b := r.Bonus; b.Enabled() && (b.Chance > 100 || b.Lifetime <= 0 || b.Points <= 0) {
+		return fmt.Errorf("the bonus needs a chance up to 100, a positive lifetime and positive points, got %d%%, %d and %d", b.Chance, b.Lifetime, b.Points)
+	}
+	kinds := map[string]bool{}
+	for _, t := range r.Foods {
+		switch {
+		case t.Kind == "":
+			return fmt.Errorf("every food type needs a kind")
+		case kinds[t.Kind]:
+			return fmt.Errorf("food type %q is listed twice", t.Kind)
+		case t.Weight <= 0:
+			return fmt.Errorf("food type %q: the weight must be positive, got %d", t.Kind, t.Weight)
+		case t.Lifetime < 0:
+			return fmt.Errorf("food type %q: the lifetime must not be negative, got %d", t.Kind, t.Lifetime)
+		}
+		kinds[t.Kind] = true
+	}
+	if len(r.Foods) > 0 && len(r.edibleFoodTypes()) == 0 {
+		return fmt.Errorf("at least one food type must not be deadly")
+	}
+	return nil
+}
+
+// foodCells returns the number of cells food may appear on, not counting the random portals
+func (r Rules) foodCells() int {
+	if r.Level == nil {
+		return r.Board.Width * r.Board.Height
+	}
+	return countCells(r.Board, r.Level.AllowsFood)
+}
+
+// countCells returns the number of cells of the board where allowed returns true
+func countCells(board vars.Board, allowed func(vars.Point) bool) int {
+	cells := 0
+	for y := 0; y < board.Height; y++ {
+		for x := 0; x < board.Width; x++ {
+			if allowed(vars.Point{X: x, Y: y}) {
+				cells++
+			}
+		}
+	}
+	return cells
+}
+
+// State represents the whole state of a game at a given moment
+type State struct {
+	Rules        Rules          // The rules the game is played with
+	Seed         int64          // The seed the game was started with
+	Tick         int            // The number of steps taken since the start of the game
+	RNG          rng.Rand       // The random source used for everything random in the game
+	Snakes       []Snake        // The snake of each player
+	Foods        []food.Food    // The food the snakes are looking for, one for all of them or one for each, then the deadly food
+	Bonus        food.Bonus     // The bonus food, when there's one on the board
+	Portals      []level.Portal // The portals of the level and the random ones, they don't change during a game
+	Scores       []int          // The current score of each player
+	MoveInterval time.Duration  // The time between two moves, which shrinks as the snakes eat
+	GameOver     bool           // Whether the game is over
+	GameWon      bool           // Whether a player has won the game
+	Winner       int            // The player who won, or outlived the others, -1 while nobody has or on a draw
+}
+
+// Input represents the players' input for a single step
+type Input struct {
+	Turns [][]vars.Point // The directions requested by each player since the previous step, in order
+}
+
+// Add adds a turn requested by a player
+func (in *Input) Add(player int, direction vars.Point) {
+	for len(in.Turns) <= player {
+		in.Turns = append(in.Turns, nil)
+	}
+	in.Turns[player] = append(in.Turns[player], direction)
+}
+
+// NewState creates the state of a new game, the same rules and seed always give the same game.
+// The rules must be valid
+func NewState(rules Rules, seed int64) State {
+	state := State{
+		Rules:        rules,
+		Seed:         seed,
+		RNG:          *rng.New(seed),
+		Snakes:       NewSnakes(rules),
+		Scores:       make([]int, rules.PlayerCount()),
+		MoveInterval: rules.StartInterval,
+		Winner:       -1,
+	}
+	state.Portals, _ = Portals(rules, seed) // Validate makes sure they all fit
+	state.Foods = make([]food.Food, rules.FoodSlots())
+	for i := range state.Foods {
+		state.Foods[i].Position = vars.Point{X: -1, Y: -1} // Off the board until it's placed
+	}
+	for i := range state.Foods {
+		state.placeFood(i)
+	}
+	return state
+}
+
+// Step moves the snakes once and returns the resulting state along with the events that occurred
+func Step(state State, input Input) (State, []event.Event) {
+	// Nothing moves anymore once the game has ended
+	if state.GameOver || state.GameWon {
+		return state, nil
+	}
+
+	// Copy what changes so the previous state is left as it was
+	state.Snakes = append([]Snake(nil), state.Snakes...)
+	state.Foods = append([]food.Food(nil), state.Foods...)
+	state.Scores = append([]int(nil), state.Scores...)
+
+	for player, turns := range input.Turns {
+		if player < len(state.Snakes) && !state.Snakes[player].Dead {
+			for _, turn := range turns {
+				state.Snakes[player].Turn(turn)
+			}
+		}
+	}
+	state.Tick++
+	for i := range state.Snakes {
+		if !state.Snakes[i].Dead {
+			state.Snakes[i].Move(state.Rules.Board)
+			state.teleport(i)
+		}
+	}
+	events := state.checkCollisions()
+	if !state.GameOver && !state.GameWon {
+		events = append(events, state.checkBonus()...)
+		state.expireFoods()
+	}
+	return state, events
+}
@@ -0,0 +1,740 @@
+package engine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"GoSnake/event"
+	"GoSnake/food"
+	"GoSnake/level"
+	"GoSnake/rng"
+	"GoSnake/vars"
+)
+
+// directions are the turns the test players pick from
+var directions = []vars.Point{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}}
+
+// game returns a game with a snake, head first, going in a direction and the food on a cell
+func game(body []vars.Point, direction, f vars.Point) State {
+	state := NewState(DefaultRules(), 1)
+	state.Snakes[0] = Snake{Body: body, Direction: direction}
+	state.Foods[0] = food.Food{Position: f, Kind: food.Normal}
+	return state
+}
+
+func TestStep(t *testing.T) {
+	far := vars.Point{X: 40, Y: 40}
+	edge := vars.DefaultBoard.Width
+	tests := []struct {
+		name   string
+		state  State
+		input  Input
+		head   vars.Point    // The head after the step
+		length int           // The length after the step
+		events []event.Event // The events of the step
+	}{
+		{"straight", game([]vars.Point{{X: 5, Y: 5}, {X: 4, Y: 5}}, right, far), Input{}, vars.Point{X: 6, Y: 5}, 2, nil},
+		{"turn", game([]vars.Point{{X: 5, Y: 5}, {X: 4, Y: 5}}, right, far), Input{Turns: [][]vars.Point{{up}}}, vars.Point{X: 5, Y: 4}, 2, nil},
+		{"no turning back", game([]vars.Point{{X: 5, Y: 5}, {X: 4, Y: 5}}, right, far), Input{Turns: [][]vars.Point{{left}}}, vars.Point{X: 6, Y: 5}, 2, nil},
+		{"eat", game([]vars.Point{{X: 5, Y: 5}}, right, vars.Point{X: 6, Y: 5}), Input{}, vars.Point{X: 6, Y: 5}, 1,
+			[]event.Event{event.FoodEaten{Position: vars.Point{X: 6, Y: 5}, Score: 1, Kind: food.Normal}}},
+		{"hit the top", game([]vars.Point{{X: 5, Y: 0}}, up, far), Input{}, vars.Point{X: 5, Y: -1}, 1,
+			[]event.Event{event.SnakeDied{Cause: event.HitWall, Position: vars.Point{X: 5, Y: -1}}}},
+		{"hit the right edge", game([]vars.Point{{X: edge - 1, Y: 3}}, right, far), Input{}, vars.Point{X: edge, Y: 3}, 1,
+			[]event.Event{event.SnakeDied{Cause: event.HitWall, Position: vars.Point{X: edge, Y: 3}}}},
+		{"hit itself", game([]vars.Point{{X: 5, Y: 5}, {X: 5, Y: 6}, {X: 6, Y: 6}, {X: 6, Y: 5}, {X: 6, Y: 4}}, down, far), Input{Turns: [][]vars.Point{{right}}}, vars.Point{X: 6, Y: 5}, 5,
+			[]event.Event{event.SnakeDied{Cause: event.HitSelf, Position: vars.Point{X: 6, Y: 5}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, events := Step(tt.state, tt.input)
+			if head := state.Snakes[0].Body[0]; head != tt.head || len(state.Snakes[0].Body) != tt.length {
+				t.Errorf("head %v and length %d, want %v and %d", head, len(state.Snakes[0].Body), tt.head, tt.length)
+			}
+			if !reflect.DeepEqual(events, tt.events) {
+				t.Errorf("events %v, want %v", events, tt.events)
+			}
+			dead := false
+			for _, e := range tt.events {
+				_, died := e.(event.SnakeDied)
+				dead = dead || died
+			}
+			if state.GameOver != dead {
+				t.Errorf("game over %v, want %v", state.GameOver, dead)
+			}
+		})
+	}
+}
+
+func TestEat(t *testing.T) {
+	state := game([]vars.Point{{X: 5, Y: 5}}, vars.Point{X: 1}, vars.Point{X: 6, Y: 5})
+	rules := state.Rules
+	state, _ = Step(state, Input{})
+	if want := rules.StartInterval - rules.IntervalStep; state.Scores[0] != 1 || state.MoveInterval != want {
+		t.Errorf("score %d and interval %v after eating, want 1 and %v", state.Scores[0], state.MoveInterval, want)
+	}
+	state.Foods[0].Position = vars.Point{X: 40, Y: 40}
+	state, _ = Step(state, Input{})
+	if len(state.Snakes[0].Body) != 2 {
+		t.Errorf("length %d on the move after eating, want 2", len(state.Snakes[0].Body))
+	}
+
+	// The snake never gets faster than the shortest interval
+	state.MoveInterval = rules.MinInterval
+	state.Foods[0].Position = vars.Point{X: 8, Y: 5}
+	if state, _ = Step(state, Input{}); state.MoveInterval != rules.MinInterval {
+		t.Errorf("interval %v after eating at full speed, want %v", state.MoveInterval, rules.MinInterval)
+	}
+}
+
+func TestFoodTypes(t *testing.T) {
+	rules := DefaultRules()
+	rules.Foods = []food.Type{
+		{Kind: food.Normal, Weight: 4, Points: 1, Grow: 1, Interval: -10 * time.Millisecond},
+		{Kind: "gold", Weight: 1, Points: 5, Grow: 2},
+		{Kind: "slow", Weight: 1, Interval: 20 * time.Millisecond},
+		{Kind: "shrink", Weight: 1, Points: -2, Grow: -3},
+		{Kind: "poison", Weight: 1, Deadly: true},
+	}
+	tests := []struct {
+		name     string
+		kind     string
+		grow     int           // The growth still to come before eating
+		interval time.Duration // The time between two moves before eating
+		score    int           // The score after eating, it was 1 before
+		length   int           // The length after eating, it was 3 before
+		growth   int           // The growth still to come after eating
+		want     time.Duration // The time between two moves after eating
+		died     bool
+	}{
+		{"normal", food.Normal, 0, 100 * time.Millisecond, 2, 3, 1, 90 * time.Millisecond, false},
+		{"normal at full speed", food.Normal, 0, rules.MinInterval, 2, 3, 1, rules.MinInterval, false},
+		{"gold", "gold", 0, 100 * time.Millisecond, 6, 3, 2, 100 * time.Millisecond, false},
+		{"slow", "slow", 0, 100 * time.Millisecond, 1, 3, 0, 120 * time.Millisecond, false},
+		{"slow at the start speed", "slow", 0, rules.StartInterval, 1, 3, 0, rules.StartInterval, false},
+		{"shrink", "shrink", 0, 100 * time.Millisecond, 0, 1, 0, 100 * time.Millisecond, false},
+		{"shrink the growth first", "shrink", 2, 100 * time.Millisecond, 0, 2, 0, 100 * time.Millisecond, false},
+		{"poison", "poison", 0, 100 * time.Millisecond, 1, 3, 0, 100 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := game([]vars.Point{{X: 5, Y: 5}, {X: 4, Y: 5}, {X: 3, Y: 5}}, right, vars.Point{X: 6, Y: 5})
+			state.Rules = rules
+			state.Foods[0].Kind = tt.kind
+			state.Scores[0] = 1
+			state.Snakes[0].GrowCounter = tt.grow
+			state.MoveInterval = tt.interval
+			state, events := Step(state, Input{})
+
+			if state.Scores[0] != tt.score || len(state.Snakes[0].Body) != tt.length || state.Snakes[0].GrowCounter != tt.growth {
+				t.Errorf("score %d, length %d and growth %d, want %d, %d and %d", state.Scores[0], len(state.Snakes[0].Body), state.Snakes[0].GrowCounter, tt.score, tt.length, tt.growth)
+			}
+			if state.Snakes[0].Body[0] != (vars.Point{X: 6, Y: 5}) {
+				t.Errorf("the head is on %v after shrinking", state.Snakes[0].Body[0])
+			}
+			if !tt.died && state.MoveInterval != tt.want {
+				t.Errorf("interval %v, want %v", state.MoveInterval, tt.want)
+			}
+			want := []event.Event{event.FoodEaten{Position: vars.Point{X: 6, Y: 5}, Score: tt.score, Kind: tt.kind}}
+			if tt.died {
+				want = append(want, event.SnakeDied{Cause: event.Poisoned, Position: vars.Point{X: 6, Y: 5}, Score: tt.score})
+			}
+			if !reflect.DeepEqual(events, want) || state.GameOver != tt.died {
+				t.Errorf("events %v and game over %v, want %v", events, state.GameOver, want)
+			}
+		})
+	}
+}
+
+func TestDeadlyFood(t *testing.T) {
+	rules := DefaultRules()
+	rules.Foods = []food.Type{{Kind: food.Normal, Weight: 1, Points: 1, Grow: 1}, {Kind: "poison", Weight: 100, Deadly: true, Lifetime: 5}}
+	for seed := int64(0); seed < 20; seed++ {
+		state := NewState(rules, seed)
+		if state.Foods[0].Kind != food.Normal {
+			t.Fatalf("seed %d: the food looked for is %q, want it edible", seed, state.Foods[0].Kind)
+		}
+		if len(state.Foods) != 2 {
+			continue // The poison wasn't picked
+		}
+		poison := state.Foods[1]
+		if poison.Kind != "poison" || poison.Expires != 5 || poison.Position == state.Foods[0].Position {
+			t.Fatalf("seed %d: the deadly food is %+v next to the food on %v", seed, poison, state.Foods[0].Position)
+		}
+
+		// It goes away once its time is up
+		state.Tick = 4
+		if state.expireFoods(); len(state.Foods) != 2 {
+			t.Fatalf("seed %d: the deadly food went away at tick 4", seed)
+		}
+		state.Tick = 5
+		if state.expireFoods(); len(state.Foods) != 1 {
+			t.Fatalf("seed %d: the deadly food is still there at tick 5", seed)
+		}
+
+		// Until then it kills the snake running into it, whoever it is
+		state = NewState(rules, seed)
+		head := rules.Board.Neighbor(poison.Position, left)
+		if !rules.Board.Contains(head) || head == state.Foods[0].Position {
+			continue
+		}
+		state.Snakes[0] = Snake{Body: []vars.Point{head}, Direction: right}
+		state, events := Step(state, Input{})
+		want := []event.Event{
+			event.FoodEaten{Position: poison.Position, Kind: "poison"},
+			event.SnakeDied{Cause: event.Poisoned, Position: poison.Position},
+		}
+		if !reflect.DeepEqual(events, want) || !state.GameOver || len(state.Foods) != 1 {
+			t.Errorf("seed %d: events %v and foods %v after running into the deadly food, want %v", seed, events, state.Foods, want)
+		}
+		return
+	}
+	t.Fatal("no game started with a deadly food")
+}
+
+func TestFoodKinds(t *testing.T) {
+	rules := DefaultRules()
+	rules.Foods = []food.Type{{Kind: food.Normal, Weight: 1, Points: 1}, {Kind: "gold", Weight: 1, Points: 3}}
+	seen := map[string]bool{}
+	for seed := int64(0); seed < 50; seed++ {
+		seen[NewState(rules, seed).Foods[0].Kind] = true
+	}
+	if len(seen) != 2 || !seen[food.Normal] || !seen["gold"] {
+		t.Errorf("the food kinds of 50 games are %v, want both kinds", seen)
+	}
+	if rules.FoodType("unknown").Kind != food.Normal {
+		t.Errorf("an unknown kind isn't the first type")
+	}
+}
+
+func TestBonusSpawn(t *testing.T) {
+	rules := DefaultRules()
+	rules.Bonus = BonusRules{Chance: 100, Lifetime: 10, Points: 5}
+	for seed := int64(0); seed < 20; seed++ {
+		state := game([]vars.Point{{X: 5, Y: 5}}, right, vars.Point{X: 6, Y: 5})
+		state.Rules = rules
+		state.RNG = *rng.New(seed)
+		state, _ = Step(state, Input{})
+		b := state.Bonus
+		if !b.Active || b.Spawned != 1 || b.Expires != 11 {
+			t.Fatalf("seed %d: got the bonus %+v, want one from tick 1 to 11", seed, b)
+		}
+		if b.Position == state.Foods[0].Position || !rules.Board.Contains(b.Position) {
+			t.Fatalf("seed %d: the bonus is on %v with the food on %v", seed, b.Position, state.Foods[0].Position)
+		}
+
+		// It stays until the tick it expires on, away from the snake
+		state.Snakes[0] = Snake{Body: []vars.Point{{X: 0, Y: 0}}, Direction: right}
+		state.Foods[0].Position = vars.Point{X: 0, Y: 40}
+		state.Bonus.Position = vars.Point{X: 40, Y: 40}
+		for state.Tick < 10 {
+			state, _ = Step(state, Input{})
+		}
+		if !state.Bonus.Active {
+			t.Fatalf("seed %d: the bonus expired on tick %d, before %d", seed, state.Tick, b.Expires)
+		}
+		if state, _ = Step(state, Input{}); state.Bonus.Active {
+			t.Fatalf("seed %d: the bonus is still there on tick %d", seed, state.Tick)
+		}
+	}
+
+	// Without bonuses the food is placed as before they existed
+	state := game([]vars.Point{{X: 5, Y: 5}}, right, vars.Point{X: 6, Y: 5})
+	withChance := state
+	withChance.Rules.Bonus = BonusRules{Chance: 0, Lifetime: 10, Points: 5}
+	state, _ = Step(state, Input{})
+	withChance, _ = Step(withChance, Input{})
+	if withChance.Bonus.Active || withChance.RNG != state.RNG || withChance.Foods[0] != state.Foods[0] {
+		t.Errorf("a game without bonuses drew from the random source for them")
+	}
+}
+
+// cramped returns a level where food may only appear on two cells, the spawn and a corner
+func cramped() *level.Level {
+	l := level.New("cramped", 4, 4)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if p := (vars.Point{X: x, Y: y}); p != l.Spawn && p != (vars.Point{X: 3, Y: 3}) {
+				l.Set(p, level.NoFood)
+			}
+		}
+	}
+	return l
+}
+
+func TestBonusWithoutRoom(t *testing.T) {
+	// The food of each player takes one of the two cells allowed
+	rules := DefaultRules().WithLevel(cramped())
+	rules.Players, rules.SeparateFood = 2, true
+	rules.Bonus = BonusRules{Chance: 100, Lifetime: 10, Points: 5}
+	if err := rules.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	state := NewState(rules, 1)
+	if state.Foods[0].Position == state.Foods[1].Position {
+		t.Fatalf("both foods are on %v", state.Foods[0].Position)
+	}
+	state.maybeSpawnBonus()
+	if state.Bonus.Active {
+		t.Errorf("a bonus spawned on %v with no cell left for it", state.Bonus.Position)
+	}
+}
+
+func TestFoodAvoidsBonus(t *testing.T) {
+	rules := DefaultRules()
+	rules.Board.Width, rules.Board.Height = 3, 2
+	bonus := vars.Point{X: 1, Y: 1}
+	for seed := int64(0); seed < 50; seed++ {
+		state := NewState(rules, seed)
+		state.Bonus = food.Bonus{Position: bonus, Spawned: 0, Expires: 10, Active: true}
+		state.placeFood(0)
+		if state.Foods[0].Position == bonus {
+			t.Fatalf("seed %d: the food was placed on the bonus", seed)
+		}
+	}
+}
+
+func TestBonusEaten(t *testing.T) {
+	tests := []struct {
+		name   string
+		spawn  int // The tick the bonus appeared on, it lasts 10 ticks and eating it takes the game to tick 21
+		score  int // The score before eating it
+		points int
+		won    bool
+	}{
+		{"right away", 20, 0, 5, false},
+		{"half way", 15, 0, 2, false},
+		{"on its last tick", 11, 0, 1, false},
+		{"winning", 20, 23, 5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := game([]vars.Point{{X: 5, Y: 5}}, right, vars.Point{X: 40, Y: 40})
+			state.Rules.Bonus = BonusRules{Chance: 50, Lifetime: 10, Points: 5}
+			state.Tick = 20
+			state.Scores[0] = tt.score
+			state.Bonus = food.Bonus{Position: vars.Point{X: 6, Y: 5}, Spawned: tt.spawn, Expires: tt.spawn + 10, Active: true}
+			state, events := Step(state, Input{})
+
+			want := []event.Event{event.BonusEaten{Position: vars.Point{X: 6, Y: 5}, Points: tt.points, Score: tt.score + tt.points}}
+			if tt.won {
+				want = append(want, event.GameWon{Score: tt.score + tt.points})
+			}
+			if !reflect.DeepEqual(events, want) || state.Scores[0] != tt.score+tt.points || state.GameWon != tt.won {
+				t.Errorf("events %v, score %d and won %v, want %v", events, state.Scores[0], state.GameWon, want)
+			}
+			if state.Bonus.Active {
+				t.Errorf("the bonus is still there after it was eaten")
+			}
+		})
+	}
+}
+
+func TestPortalTeleport(t *testing.T) {
+	state := game([]vars.Point{{X: 5, Y: 5}, {X: 4, Y: 5}, {X: 3, Y: 5}}, right, vars.Point{X: 40, Y: 40})
+	state.Portals = []level.Portal{{A: vars.Point{X: 6, Y: 5}, B: vars.Point{X: 20, Y: 10}}}
+	bodies := [][]vars.Point{
+		{{X: 20, Y: 10}, {X: 5, Y: 5}, {X: 4, Y: 5}},
+		{{X: 21, Y: 10}, {X: 20, Y: 10}, {X: 5, Y: 5}},
+		{{X: 22, Y: 10}, {X: 21, Y: 10}, {X: 20, Y: 10}},
+	}
+	for i, want := range bodies {
+		var events []event.Event
+		state, events = Step(state, Input{})
+		if !reflect.DeepEqual(state.Snakes[0].Body, want) || events != nil {
+			t.Fatalf("move %d: the body is %v with events %v, want %v", i+1, state.Snakes[0].Body, events, want)
+		}
+	}
+
+	// Going back through the other end, the food on the exit is eaten
+	state.Snakes[0] = Snake{Body: []vars.Point{{X: 19, Y: 10}, {X: 18, Y: 10}}, Direction: right}
+	state.Foods[0].Position = vars.Point{X: 6, Y: 5}
+	state, events := Step(state, Input{})
+	if state.Snakes[0].Body[0] != (vars.Point{X: 6, Y: 5}) || len(events) != 1 || state.Scores[0] != 1 {
+		t.Errorf("the head is on %v with events %v, want it out of the first end eating the food", state.Snakes[0].Body[0], events)
+	}
+}
+
+// corridor returns a level walled in but for the row of the spawn
+func corridor() *level.Level {
+	l := level.New("corridor", 8, 8)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if y != l.Spawn.Y {
+				l.Set(vars.Point{X: x, Y: y}, level.Wall)
+			}
+		}
+	}
+	return l
+}
+
+func TestPortalsWithoutRoom(t *testing.T) {
+	rules := DefaultRules().WithLevel(corridor())
+	rules.Portals = 4
+	portals, err := Portals(rules, 1)
+	if err == nil || len(portals) != 3 {
+		t.Errorf("got the portals %v and error %v, want the 3 that fit and an error", portals, err)
+	}
+}
+
+func TestPortalsKeepFoodOff(t *testing.T) {
+	rules := DefaultRules()
+	rules.Board = vars.Board{Width: 8, Height: 8}
+	rules.Portals = 8 // An eighth of the cells
+	for seed := int64(0); seed < 50; seed++ {
+		state := NewState(rules, seed)
+		if portals, err := Portals(rules, seed); err != nil || len(state.Portals) != rules.Portals || !reflect.DeepEqual(state.Portals, portals) {
+			t.Fatalf("seed %d: got the portals %v, want %d placed the same every time", seed, state.Portals, rules.Portals)
+		}
+		ends := map[vars.Point]bool{}
+		for _, p := range state.Portals {
+			ends[p.A], ends[p.B] = true, true
+		}
+		spawn := state.Snakes[0].Body[0]
+		if len(ends) != 2*rules.Portals || ends[spawn] || ends[rules.Board.Neighbor(spawn, state.Snakes[0].Direction)] {
+			t.Fatalf("seed %d: the portal ends %v overlap or stand in the snake's way", seed, state.Portals)
+		}
+
+		// The food never lands on a portal, when the game starts or once it's eaten
+		for i := 0; i < 20; i++ {
+			if ends[state.Foods[0].Position] {
+				t.Fatalf("seed %d: the food is on the portal end %v", seed, state.Foods[0].Position)
+			}
+			state.Snakes[0] = Snake{Body: []vars.Point{rules.Board.Neighbor(state.Foods[0].Position, left)}, Direction: right}
+			state, _ = Step(state, Input{})
+		}
+	}
+}
+
+func TestWin(t *testing.T) {
+	state := game([]vars.Point{{X: 5, Y: 5}}, vars.Point{X: 1}, vars.Point{X: 6, Y: 5})
+	state.Rules.WinScore = 3
+	state.Scores[0] = 2
+	state, events := Step(state, Input{})
+	if !state.GameWon || len(events) != 2 || events[1] != (event.GameWon{Score: 3}) {
+		t.Fatalf("won %v with events %v, want a win", state.GameWon, events)
+	}
+	if next, events := Step(state, Input{}); !reflect.DeepEqual(next, state) || events != nil {
+		t.Errorf("the game went on after it was won")
+	}
+}
+
+// versus returns a game between two snakes, head first, with the food far from both
+func versus(first, second Snake) State {
+	rules := DefaultRules()
+	rules.Players = 2
+	state := NewState(rules, 1)
+	state.Snakes = []Snake{first, second}
+	state.Foods[0] = food.Food{Position: vars.Point{X: 40, Y: 40}, Kind: food.Normal}
+	return state
+}
+
+func TestVersus(t *testing.T) {
+	died := func(player int, cause event.DeathCause, x, y int) event.Event {
+		return event.SnakeDied{Cause: cause, Position: vars.Point{X: x, Y: y}, Player: player}
+	}
+	tests := []struct {
+		name   string
+		first  Snake
+		second Snake
+		events []event.Event
+		winner int
+	}{
+		{"passing by", Snake{Body: []vars.Point{{X: 5, Y: 5}, {X: 4, Y: 5}}, Direction: right}, Snake{Body: []vars.Point{{X: 7, Y: 6}, {X: 8, Y: 6}}, Direction: left},
+			nil, -1},
+		{"heads on the same cell", Snake{Body: []vars.Point{{X: 5, Y: 5}, {X: 4, Y: 5}}, Direction: right}, Snake{Body: []vars.Point{{X: 7, Y: 5}, {X: 8, Y: 5}}, Direction: left},
+			[]event.Event{died(0, event.HitSnake, 6, 5), died(1, event.HitSnake, 6, 5)}, -1},
+		{"swapping heads", Snake{Body: []vars.Point{{X: 5, Y: 5}, {X: 4, Y: 5}}, Direction: right}, Snake{Body: []vars.Point{{X: 6, Y: 5}, {X: 7, Y: 5}}, Direction: left},
+			[]event.Event{died(0, event.HitSnake, 6, 5), died(1, event.HitSnake, 5, 5)}, -1},
+		{"into the other body", Snake{Body: []vars.Point{{X: 5, Y: 5}, {X: 5, Y: 4}}, Direction: down}, Snake{Body: []vars.Point{{X: 8, Y: 6}, {X: 7, Y: 6}, {X: 6, Y: 6}, {X: 5, Y: 6}, {X: 4, Y: 6}}, Direction: right},
+			[]event.Event{died(0, event.HitSnake, 5, 6)}, 1},
+		{"into a wall", Snake{Body: []vars.Point{{X: 5, Y: 5}}, Direction: right}, Snake{Body: []vars.Point{{X: 7, Y: 0}}, Direction: up},
+			[]event.Event{died(1, event.HitWall, 7, -1)}, 0},
+		{"both into walls", Snake{Body: []vars.Point{{X: 0, Y: 5}}, Direction: left}, Snake{Body: []vars.Point{{X: 7, Y: 0}}, Direction: up},
+			[]event.Event{died(0, event.HitWall, -1, 5), died(1, event.HitWall, 7, -1)}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, events := Step(versus(tt.first, tt.second), Input{})
+			if !reflect.DeepEqual(events, tt.events) {
+				t.Errorf("events %v, want %v", events, tt.events)
+			}
+			if state.GameOver != (tt.events != nil) || state.Winner != tt.winner {
+				t.Errorf("game over %v and winner %d, want %v and %d", state.GameOver, state.Winner, tt.events != nil, tt.winner)
+			}
+		})
+	}
+}
+
+func TestVersusWin(t *testing.T) {
+	state := versus(Snake{Body: []vars.Point{{X: 5, Y: 5}}, Direction: right}, Snake{Body: []vars.Point{{X: 5, Y: 8}}, Direction: right})
+	state.Rules.WinScore = 3
+	state.Scores = []int{1, 2}
+	state.Foods[0].Position = vars.Point{X: 6, Y: 8}
+	state, events := Step(state, Input{})
+	want := []event.Event{event.FoodEaten{Position: vars.Point{X: 6, Y: 8}, Score: 3, Kind: food.Normal, Player: 1}, event.GameWon{Score: 3, Player: 1}}
+	if !reflect.DeepEqual(events, want) || !state.GameWon || state.Winner != 1 || !reflect.DeepEqual(state.Scores, []int{1, 3}) {
+		t.Errorf("events %v, won %v by %d with scores %v, want %v", events, state.GameWon, state.Winner, state.Scores, want)
+	}
+}
+
+func TestVersusPlaysOn(t *testing.T) {
+	rules := DefaultRules()
+	rules.Players = 3
+	state := NewState(rules, 1)
+	state.Snakes = []Snake{
+		{Body: []vars.Point{{X: 5, Y: 4}}, Direction: down},
+		{Body: []vars.Point{{X: 5, Y: 5}, {X: 4, Y: 5}}, Direction: right},
+		{Body: []vars.Point{{X: 20, Y: 20}}, Direction: down},
+	}
+	state.Foods[0].Position = vars.Point{X: 40, Y: 40}
+
+	// The first snake runs into the second one, the others play on
+	state, events := Step(state, Input{})
+	if want := []event.Event{event.SnakeDied{Cause: event.HitSnake, Position: vars.Point{X: 5, Y: 5}}}; !reflect.DeepEqual(events, want) {
+		t.Fatalf("events %v, want %v", events, want)
+	}
+	if state.GameOver || state.Winner != -1 || !state.Snakes[0].Dead {
+		t.Fatalf("game over %v with winner %d after the first snake died, want the others to play on", state.GameOver, state.Winner)
+	}
+
+	// The dead snake neither moves, turns nor stands in the way
+	dead := state.Snakes[0]
+	state.Snakes[2] = Snake{Body: []vars.Point{{X: 5, Y: 6}}, Direction: up}
+	var input Input
+	input.Add(0, left)
+	state, events = Step(state, input)
+	if events != nil || !reflect.DeepEqual(state.Snakes[0], dead) || state.Snakes[2].Body[0] != dead.Body[0] {
+		t.Fatalf("events %v with the dead snake %+v, want nothing to happen to it", events, state.Snakes[0])
+	}
+
+	// The last snake alive wins once the other one dies
+	state.Snakes[2] = Snake{Body: []vars.Point{{X: 5, Y: 0}}, Direction: up}
+	state, events = Step(state, Input{})
+	if !state.GameOver || state.Winner != 1 || len(events) != 1 {
+		t.Errorf("game over %v with winner %d and events %v, want the second player to win", state.GameOver, state.Winner, events)
+	}
+}
+
+func TestSeparateFood(t *testing.T) {
+	rules := DefaultRules()
+	rules.Players, rules.SeparateFood = 2, true
+	for seed := int64(0); seed < 20; seed++ {
+		state := NewState(rules, seed)
+		if len(state.Foods) != 2 || state.Foods[0].Position == state.Foods[1].Position {
+			t.Fatalf("seed %d: the foods are %v, want one for each player on different cells", seed, state.Foods)
+		}
+	}
+
+	// Each snake only eats its own food, the other one goes over it
+	state := NewState(rules, 1)
+	state.Snakes = []Snake{{Body: []vars.Point{{X: 5, Y: 5}}, Direction: right}, {Body: []vars.Point{{X: 5, Y: 8}}, Direction: right}}
+	state.Foods[0].Position, state.Foods[1].Position = vars.Point{X: 6, Y: 8}, vars.Point{X: 6, Y: 5}
+	state, events := Step(state, Input{})
+	if events != nil || !reflect.DeepEqual(state.Scores, []int{0, 0}) {
+		t.Fatalf("events %v and scores %v after going over the food of the other player", events, state.Scores)
+	}
+	state.Foods[1].Position = vars.Point{X: 7, Y: 8}
+	other := state.Foods[0]
+	state, events = Step(state, Input{})
+	if len(events) != 1 || !reflect.DeepEqual(state.Scores, []int{0, 1}) || state.Foods[0] != other || state.Foods[1].Position == (vars.Point{X: 7, Y: 8}) {
+		t.Errorf("events %v, scores %v and foods %v, want the second player to eat their food only", events, state.Scores, state.Foods)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	far := vars.Point{X: 30, Y: 30}
+	w, h := DefaultRules().Board.Width, DefaultRules().Board.Height
+	tests := []struct {
+		name  string
+		body  []vars.Point
+		turn  vars.Point
+		head  vars.Point  // The head after the step
+		event event.Event // The event of the step, nil if there is none
+	}{
+		{"through the top", []vars.Point{{X: 5, Y: 0}, {X: 5, Y: 1}}, up, vars.Point{X: 5, Y: h - 1}, nil},
+		{"through the bottom", []vars.Point{{X: 5, Y: h - 1}, {X: 5, Y: h - 2}}, down, vars.Point{X: 5, Y: 0}, nil},
+		{"through the left", []vars.Point{{X: 0, Y: 7}, {X: 1, Y: 7}}, left, vars.Point{X: w - 1, Y: 7}, nil},
+		{"through the right", []vars.Point{{X: w - 1, Y: 7}, {X: w - 2, Y: 7}}, right, vars.Point{X: 0, Y: 7}, nil},
+		{"into itself across the edge", []vars.Point{{X: 0, Y: 5}, {X: 0, Y: 6}, {X: w - 1, Y: 6}, {X: w - 1, Y: 5}, {X: w - 1, Y: 4}}, left, vars.Point{X: w - 1, Y: 5},
+			event.SnakeDied{Cause: event.HitSelf, Position: vars.Point{X: w - 1, Y: 5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := game(tt.body, tt.turn, far)
+			state.Rules.Board.Wrap = true
+			state, events := Step(state, Input{})
+			if head := state.Snakes[0].Body[0]; head != tt.head {
+				t.Errorf("head %v, want %v", head, tt.head)
+			}
+			if tt.event == nil && (events != nil || state.GameOver) {
+				t.Errorf("events %v and game over %v, want the snake to go on", events, state.GameOver)
+			}
+			if tt.event != nil && (len(events) != 1 || events[0] != tt.event || !state.GameOver) {
+				t.Errorf("events %v and game over %v, want %v", events, state.GameOver, tt.event)
+			}
+		})
+	}
+}
+
+// arena is a small level with a wall in the middle and cells without food
+const arena = `{
+	"name": "Arena",
+	"direction": "right",
+	"map": [
+		"------",
+		"-S.#..",
+		"---#..",
+		"------"
+	]
+}`
+
+func TestLevel(t *testing.T) {
+	l, err := level.Parse([]byte(arena))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := DefaultRules().WithLevel(l)
+	if err := rules.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for seed := int64(0); seed < 50; seed++ {
+		state := NewState(rules, seed)
+		if state.Snakes[0].Body[0] != l.Spawn || state.Snakes[0].Direction != l.Direction {
+			t.Fatalf("seed %d: the snake starts on %v going %v, want the spawn of the level", seed, state.Snakes[0].Body[0], state.Snakes[0].Direction)
+		}
+		if !l.AllowsFood(state.Foods[0].Position) {
+			t.Fatalf("seed %d: the food is on %v where the level allows none", seed, state.Foods[0].Position)
+		}
+
+		// The snake eats what's in front of it then runs into the wall
+		state.Foods[0].Position = vars.Point{X: 2, Y: 1}
+		state, _ = Step(state, Input{})
+		if !l.AllowsFood(state.Foods[0].Position) {
+			t.Fatalf("seed %d: the food moved to %v where the level allows none", seed, state.Foods[0].Position)
+		}
+		state, events := Step(state, Input{})
+		if want := (event.SnakeDied{Cause: event.HitWall, Position: vars.Point{X: 3, Y: 1}, Score: 1}); len(events) != 1 || events[0] != want {
+			t.Fatalf("seed %d: events %v, want %v", seed, events, want)
+		}
+	}
+}
+
+func TestRulesWithLevel(t *testing.T) {
+	l, err := level.Parse([]byte(arena))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := DefaultRules()
+	rules.Board.Wrap = true
+	if rules = rules.WithLevel(l); rules.Board.Width != 6 || rules.Board.Height != 4 || !rules.Board.Wrap {
+		t.Errorf("got the board %+v, want the size of the level and to keep wrapping", rules.Board)
+	}
+	rules.Board.Width = 7
+	if err := rules.Validate(); err == nil || !strings.Contains(err.Error(), "is 6x4 cells but the board is 7x4") {
+		t.Errorf("got error %v for a level smaller than the board", err)
+	}
+}
+
+// play plays a game to its end, or for a number of moves, turning the snake at random with a source of its own.
+// It returns the state after every move and the events of every move
+func play(seed int64, moves int) ([]State, [][]event.Event) {
+	turns := rng.New(seed ^ 0x5eed)
+	state := NewState(DefaultRules(), seed)
+	states := []State{state}
+	var events [][]event.Event
+	for i := 0; i < moves && !state.GameOver && !state.GameWon; i++ {
+		var input Input
+		if turns.Intn(4) == 0 {
+			input.Add(0, directions[turns.Intn(len(directions))])
+		}
+		var e []event.Event
+		state, e = Step(state, input)
+		states = append(states, state)
+		events = append(events, e)
+	}
+	return states, events
+}
+
+func TestStepDeterministic(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		states, events := play(seed, 500)
+		again, againEvents := play(seed, 500)
+		if !reflect.DeepEqual(states, again) {
+			t.Fatalf("seed %d: two games with the same seed and turns went differently", seed)
+		}
+		if !reflect.DeepEqual(events, againEvents) {
+			t.Fatalf("seed %d: two games with the same seed and turns had different events", seed)
+		}
+	}
+}
+
+func TestNewStateSeeds(t *testing.T) {
+	first := NewState(DefaultRules(), 1)
+	if again := NewState(DefaultRules(), 1); !reflect.DeepEqual(first, again) {
+		t.Errorf("two games with seed 1 start differently: %+v and %+v", first, again)
+	}
+	if other := NewState(DefaultRules(), 2); first.Foods[0] == other.Foods[0] {
+		t.Errorf("games with seeds 1 and 2 have their food on the same cell %v", first.Foods[0])
+	}
+}
+
+func TestNewStateBoard(t *testing.T) {
+	rules := DefaultRules()
+	rules.Board = vars.Board{Width: 7, Height: 4}
+	board := rules.Board
+	for seed := int64(0); seed < 50; seed++ {
+		state := NewState(rules, seed)
+		if head := state.Snakes[0].Body[0]; head != (vars.Point{X: 3, Y: 2}) {
+			t.Fatalf("seed %d: the snake starts on %v, want the middle of the board", seed, head)
+		}
+		if !board.Contains(state.Foods[0].Position) {
+			t.Fatalf("seed %d: the food is on %v, outside the board", seed, state.Foods[0].Position)
+		}
+	}
+}
+
+func TestRulesValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*Rules)
+		want   string // A part of the error, empty for valid rules
+	}{
+		{"default", func(r *Rules) {}, ""},
+		{"smallest board", func(r *Rules) { r.Board.Width, r.Board.Height = 2, 2 }, ""},
+		{"board too narrow", func(r *Rules) { r.Board.Width = 1 }, "at least 2x2 cells"},
+		{"no minimum interval", func(r *Rules) { r.MinInterval = 0 }, "minimum interval must be positive"},
+		{"start faster than the minimum", func(r *Rules) { r.StartInterval = r.MinInterval - 1 }, "must not be shorter"},
+		{"negative step", func(r *Rules) { r.IntervalStep = -1 }, "must not be negative"},
+		{"nothing to win", func(r *Rules) { r.WinScore = 0 }, "winning score must be positive"},
+		{"bonus without a lifetime", func(r *Rules) { r.Bonus = BonusRules{Chance: 10, Points: 3} }, "the bonus needs"},
+		{"bonus too likely", func(r *Rules) { r.Bonus = BonusRules{Chance: 101, Lifetime: 5, Points: 3} }, "the bonus needs"},
+		{"negative portals", func(r *Rules) { r.Portals = -1 }, "random portals must be between 0"},
+		{"too many portals", func(r *Rules) { r.Board.Width, r.Board.Height, r.Portals = 8, 8, 9 }, "random portals must be between 0"},
+		{"no room for the portals", func(r *Rules) { *r = r.WithLevel(corridor()); r.Portals = 4 }, "4 random portals need 8 free cells, only 6 are left"},
+		{"a food for each player", func(r *Rules) { *r = r.WithLevel(cramped()); r.Players, r.SeparateFood = 2, true }, ""},
+		{"no room for the food", func(r *Rules) { *r = r.WithLevel(cramped()); r.Portals = 1 }, "1 foods don't fit in the 0 cells left"},
+		{"no room for the foods", func(r *Rules) { *r = r.WithLevel(cramped()); r.Players, r.SeparateFood, r.Portals = 2, true, 1 }, "2 foods don't fit in the 0 cells left"},
+		{"food without a kind", func(r *Rules) { r.Foods = []food.Type{{Weight: 1}} }, "every food type needs a kind"},
+		{"food listed twice", func(r *Rules) { r.Foods = []food.Type{{Kind: "a", Weight: 1}, {Kind: "a", Weight: 2}} }, `food type "a" is listed twice`},
+		{"four players", func(r *Rules) { r.Players = MaxPlayers }, ""},
+		{"too many players", func(r *Rules) { r.Players = MaxPlayers + 1 }, "the number of players must be between 1"},
+		{"two players in a level", func(r *Rules) { *r = r.WithLevel(level.New("even", 6, 4)); r.Players = 2 }, ""},
+		{"two players on a centered spawn", func(r *Rules) { *r = r.WithLevel(level.New("odd", 7, 5)); r.Players = 2 }, "the second snake would start on the first one"},
+		{"only deadly food", func(r *Rules) { r.Foods = []food.Type{{Kind: "a", Weight: 1, Deadly: true}} }, "at least one food type must not be deadly"},
+		{"negative lifetime", func(r *Rules) { r.Foods = []food.Type{{Kind: "a", Weight: 1, Lifetime: -1}} }, `food type "a": the lifetime must not be negative`},
+		{"food never appearing", func(r *Rules) { r.Foods = []food.Type{{Kind: "a"}} }, `food type "a": the weight must be positive`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := DefaultRules()
+			tt.change(&rules)
+			err := rules.Validate()
+			if (err == nil) != (tt.want == "") || (err != nil && !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
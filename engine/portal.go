@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"fmt"
+
+	"GoSnake/level"
+	"GoSnake/rng"
+	"GoSnake/vars"
+)
+
+// portalSalt is mixed into the seed of a game to place its random portals, they come from their own random
+// source so games without them play the same as before portals existed, and they can be placed again from the seed
+const portalSalt = 0x706f7274616c73
+
+// Portals returns the portals of a game: those of the level, then the random ones. It returns an error along
+// with the portals placed so far when there are too few free cells for the random ones, which Validate rules out
+func Portals(rules Rules, seed int64) ([]level.Portal, error) {
+	var portals []level.Portal
+	if rules.Level != nil {
+		portals = append(portals, rules.Level.Portals...)
+	}
+	if rules.Portals == 0 {
+		return portals, nil
+	}
+
+	r := rng.New(seed ^ portalSalt)
+	free := portalAllowed(rules, &portals)
+	pick := func() (vars.Point, error) {
+		if countCells(rules.Board, free) == 0 {
+			return vars.Point{}, fmt.Errorf("no free cell left for the %d random portals", rules.Portals)
+		}
+		p := vars.Point{X: r.Intn(rules.Board.Width), Y: r.Intn(rules.Board.Height)}
+		for !free(p) {
+			p = vars.Point{X: r.Intn(rules.Board.Width), Y: r.Intn(rules.Board.Height)}
+		}
+		return p, nil
+	}
+	for i := 0; i < rules.Portals; i++ {
+		a, err := pick()
+		if err != nil {
+			return portals, err
+		}
+		portals = append(portals, level.Portal{A: a, B: a}) // Taken so the other end lands elsewhere
+		if portals[len(portals)-1].B, err = pick(); err != nil {
+			return portals[:len(portals)-1], err
+		}
+	}
+	return portals, nil
+}
+
+// portalAllowed returns whether a random portal may have an end on a cell: the random portals are kept off
+// the walls, the other portals and the first cells of the snakes' way
+func portalAllowed(rules Rules, portals *[]level.Portal) func(vars.Point) bool {
+	snakes := NewSnakes(rules)
+	return func(p vars.Point) bool {
+		if rules.Level != nil && rules.Level.IsWall(p) {
+			return false
+		}
+		for _, snake := range snakes {
+			if p == snake.Body[0] || p == rules.Board.Neighbor(snake.Body[0], snake.Direction) {
+				return false
+			}
+		}
+		return portalAt(*portals, p) < 0
+	}
+}
+
+// teleport moves the head of a snake to the other end of the portal it entered, keeping its direction.
+// The body follows through the portal as the snake keeps moving
+func (s *State) teleport(snake int) {
+	head := s.Snakes[snake].Body[0]
+	if i := portalAt(s.Portals, head); i >= 0 {
+		s.Snakes[snake].Body[0], _ = s.Portals[i].Exit(head)
+	}
+}
+
+// portalAt returns the index of the portal with an end on a cell, -1 if there's none
+func portalAt(portals []level.Portal, p vars.Point) int {
+	for i, portal := range portals {
+		if _, ok := portal.Exit(p); ok {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"time"
+)
+
+// MaxCatchUp is the largest number of moves a scheduler hands out for a single call to Add,
+// so a long stall doesn't make the snake jump across the board
+const MaxCatchUp = 5
+
+// Scheduler turns elapsed real time into moves happening at a fixed interval
+type Scheduler struct {
+	accumulator time.Duration // Time elapsed and not yet spent on moves
+	pending     int           // Moves handed out since the last call to Add
+}
+
+// Add accumulates the time elapsed since the previous call
+func (s *Scheduler) Add(elapsed time.Duration) {
+	s.accumulator += elapsed
+	s.pending = 0
+}
+
+// Next reports whether enough time has accumulated for one more move at the given interval,
+// and spends that time if so
+func (s *Scheduler) Next(interval time.Duration) bool {
+	if s.accumulator < interval {
+		return false
+	}
+	// Drop the time we're too late to catch up with
+	if s.pending >= MaxCatchUp {
+		s.accumulator = 0
+		return false
+	}
+	s.accumulator -= interval
+	s.pending++
+	return true
+}
+
+// Reset forgets all the accumulated time
+func (s *Scheduler) Reset() {
+	s.accumulator = 0
+	s.pending = 0
+}
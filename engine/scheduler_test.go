@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	const interval = 100 * time.Millisecond
+	tests := []struct {
+		name    string
+		elapsed []time.Duration // The time added before each round of moves
+		want    []int           // The moves handed out after each addition
+	}{
+		{"too soon", []time.Duration{99 * time.Millisecond}, []int{0}},
+		{"one move", []time.Duration{interval}, []int{1}},
+		{"leftover time adds up", []time.Duration{150 * time.Millisecond, 50 * time.Millisecond}, []int{1, 1}},
+		{"catching up", []time.Duration{3 * interval}, []int{3}},
+		{"at most MaxCatchUp moves", []time.Duration{MaxCatchUp * interval, 2 * interval}, []int{MaxCatchUp, 2}},
+		{"a long stall is dropped", []time.Duration{time.Minute, interval / 2, interval / 2}, []int{MaxCatchUp, 0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Scheduler
+			for i, elapsed := range tt.elapsed {
+				s.Add(elapsed)
+				moves := 0
+				for s.Next(interval) {
+					moves++
+				}
+				if moves != tt.want[i] {
+					t.Errorf("after adding %v: %d moves, want %d", elapsed, moves, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSchedulerReset(t *testing.T) {
+	var s Scheduler
+	s.Add(time.Second)
+	s.Reset()
+	if s.Next(time.Millisecond) {
+		t.Error("a move after a reset, the time should be forgotten")
+	}
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"GoSnake/vars"
+)
+
+// MaxQueuedTurns is the number of turns a snake remembers ahead of its moves
+const MaxQueuedTurns = 3
+
+// MaxPlayers is the number of snakes a board can hold
+const MaxPlayers = 4
+
+// Snake struct represents the snake in the game
+type Snake struct {
+	Body        []vars.Point // Body is a slice of points that represents the body of the snake
+	Direction   vars.Point   // Direction is the current direction of the snake
+	GrowCounter int          // GrowCounter is the number of times the snake needs to grow
+	Turns       []vars.Point // Turns holds the directions requested by the player, one is taken on each move
+	Dead        bool         // Dead is set once the snake died, it leaves the board while the others play on
+}
+
+// NewSnake function creates a new snake in the middle of the board and returns it
+func NewSnake(board vars.Board) Snake {
+	return Snake{
+		Body:      []vars.Point{board.Center()}, // Initialize the snake in the middle of the board
+		Direction: vars.Point{X: 1, Y: 0},       // The snake starts moving to the right
+	}
+}
+
+// NewSnakes creates the snake of each player. A single snake starts like in the classic game, or on the spawn
+// of the level. Several snakes start spread over the board, the second one across from the first one in a level
+func NewSnakes(rules Rules) []Snake {
+	board := rules.Board
+	if rules.PlayerCount() == 1 && rules.Level == nil {
+		return []Snake{NewSnake(board)}
+	}
+	if rules.Level != nil {
+		spawn, direction := rules.Level.Spawn, rules.Level.Direction
+		snakes := []Snake{{Body: []vars.Point{spawn}, Direction: direction}}
+		if rules.PlayerCount() > 1 {
+			across := vars.Point{X: board.Width - 1 - spawn.X, Y: board.Height - 1 - spawn.Y}
+			snakes = append(snakes, Snake{Body: []vars.Point{across}, Direction: vars.Point{X: -direction.X, Y: -direction.Y}})
+		}
+		return snakes
+	}
+
+	// Each snake starts in its own quarter of the board, going round so none runs into another at the start
+	left, right := board.Width/4, board.Width-1-board.Width/4
+	top, bottom := board.Height/4, board.Height-1-board.Height/4
+	spawns := []Snake{
+		{Body: []vars.Point{{X: left, Y: top}}, Direction: vars.Point{X: 1, Y: 0}},
+		{Body: []vars.Point{{X: right, Y: bottom}}, Direction: vars.Point{X: -1, Y: 0}},
+		{Body: []vars.Point{{X: right, Y: top}}, Direction: vars.Point{X: 0, Y: 1}},
+		{Body: []vars.Point{{X: left, Y: bottom}}, Direction: vars.Point{X: 0, Y: -1}},
+	}
+	return spawns[:rules.PlayerCount()]
+}
+
+// Turn queues a new direction for the snake and reports whether it was accepted,
+// turns that would reverse the previously queued direction are ignored
+func (s *Snake) Turn(direction vars.Point) bool {
+	// Drop the turn if the queue is full
+	if len(s.Turns) >= MaxQueuedTurns {
+		return false
+	}
+
+	// Validate the turn against the direction the snake will have when it's taken
+	last := s.Direction
+	if len(s.Turns) > 0 {
+		last = s.Turns[len(s.Turns)-1]
+	}
+	if (direction.X != 0 && last.X == 0) || (direction.Y != 0 && last.Y == 0) {
+		s.Turns = append(s.Turns[:len(s.Turns):len(s.Turns)], direction) // Copy so other states sharing the queue are untouched
+		return true
+	}
+	return false
+}
+
+// Shrink takes segments away from the snake, the growth still to come first and then the tail. The head always stays
+func (s *Snake) Shrink(n int) {
+	if s.GrowCounter >= n {
+		s.GrowCounter -= n
+		return
+	}
+	n -= s.GrowCounter
+	s.GrowCounter = 0
+	s.Body = s.Body[:max(len(s.Body)-n, 1)]
+}
+
+// Move function takes the next queued turn and moves the snake in the current direction, across
+// the edges when the board wraps
+func (s *Snake) Move(board vars.Board) {
+	if len(s.Turns) > 0 {
+		s.Direction = s.Turns[0]
+		s.Turns = s.Turns[1:]
+	}
+	newHead := board.Neighbor(s.Body[0], s.Direction) // Calculate the new head of the snake
+	s.Body = append([]vars.Point{newHead}, s.Body...) // Add the new head to the body of the snake
+	if s.GrowCounter > 0 {
+		s.GrowCounter-- // If the snake needs to grow, decrease the grow counter
+	} else {
+		s.Body = s.Body[:len(s.Body)-1] // If the snake doesn't need to grow, remove the last element of the body
+	}
+}
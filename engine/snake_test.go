@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"GoSnake/vars"
+)
+
+var (
+	up    = vars.Point{X: 0, Y: -1}
+	down  = vars.Point{X: 0, Y: 1}
+	left  = vars.Point{X: -1, Y: 0}
+	right = vars.Point{X: 1, Y: 0}
+)
+
+func TestTurn(t *testing.T) {
+	tests := []struct {
+		name     string
+		turns    []vars.Point // The turns asked of a snake going right, in order
+		accepted []bool       // Whether each turn was accepted
+		queued   []vars.Point // The turns left in the queue
+	}{
+		{"into the neck", []vars.Point{left}, []bool{false}, nil},
+		{"the way it goes", []vars.Point{right}, []bool{false}, nil},
+		{"up then left", []vars.Point{up, left}, []bool{true, true}, []vars.Point{up, left}},
+		{"up then down", []vars.Point{up, down}, []bool{true, false}, []vars.Point{up}},
+		{"up twice", []vars.Point{up, up}, []bool{true, false}, []vars.Point{up}},
+		{"full queue", []vars.Point{up, left, down, right}, []bool{true, true, true, false}, []vars.Point{up, left, down}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snake := Snake{Body: []vars.Point{{X: 5, Y: 5}, {X: 4, Y: 5}}, Direction: right}
+			var accepted []bool
+			for _, turn := range tt.turns {
+				accepted = append(accepted, snake.Turn(turn))
+			}
+			if !reflect.DeepEqual(accepted, tt.accepted) {
+				t.Errorf("accepted %v, want %v", accepted, tt.accepted)
+			}
+			if !reflect.DeepEqual(snake.Turns, tt.queued) {
+				t.Errorf("queued %v, want %v", snake.Turns, tt.queued)
+			}
+			if len(snake.Turns) > MaxQueuedTurns {
+				t.Errorf("%d turns queued, more than %d", len(snake.Turns), MaxQueuedTurns)
+			}
+		})
+	}
+}
+
+func TestMoveTakesOneTurn(t *testing.T) {
+	snake := Snake{Body: []vars.Point{{X: 5, Y: 5}}, Direction: right}
+	snake.Turn(up)
+	snake.Turn(left)
+	heads := []vars.Point{{X: 5, Y: 4}, {X: 4, Y: 4}, {X: 3, Y: 4}}
+	queued := [][]vars.Point{{left}, {}, {}}
+	for i, head := range heads {
+		snake.Move(DefaultRules().Board)
+		if snake.Body[0] != head || len(snake.Turns) != len(queued[i]) {
+			t.Errorf("move %d: head %v with turns %v queued, want %v with %v", i+1, snake.Body[0], snake.Turns, head, queued[i])
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package event
+
+// Handler reacts to an event
+type Handler func(e Event)
+
+// Bus delivers published events to every subscribed handler
+type Bus struct {
+	handlers []Handler // The subscribed handlers, called in subscription order
+}
+
+// NewBus creates an event bus without subscribers
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a handler that will receive every published event
+func (b *Bus) Subscribe(handler Handler) {
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish delivers the events, in order, to every handler
+func (b *Bus) Publish(events ...Event) {
+	for _, e := range events {
+		for _, handler := range b.handlers {
+			handler(e)
+		}
+	}
+}
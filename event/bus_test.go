@@ -0,0 +1,25 @@
+package event
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPublish(t *testing.T) {
+	bus := NewBus()
+	var got []string
+	bus.Subscribe(func(e Event) {
+		if _, ok := e.(Paused); ok {
+			got = append(got, "first paused")
+		} else {
+			got = append(got, "first other")
+		}
+	})
+	bus.Subscribe(func(e Event) { got = append(got, "second") })
+
+	bus.Publish(Paused{}, Resumed{})
+	want := []string{"first paused", "second", "first other", "second"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("handled %v, want %v", got, want)
+	}
+}
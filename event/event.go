@@ -0,0 +1,94 @@
+// Package event defines what can happen during a game and delivers it to
+// whoever is interested, so features can react without touching the rules
+package event
+
+import (
+	"GoSnake/vars"
+)
+
+// Event is something that happened during a game
+type Event interface {
+	event()
+}
+
+// DeathCause tells why a snake died
+type DeathCause int
+
+const (
+	HitWall  DeathCause = iota // The snake left the board
+	HitSelf                    // The snake ran into its own body
+	Poisoned                   // The snake ate a deadly food
+	HitSnake                   // The snake ran into another snake
+)
+
+// String returns a readable name for the cause
+func (c DeathCause) String() string {
+	switch c {
+	case HitWall:
+		return "hit wall"
+	case HitSelf:
+		return "hit self"
+	case Poisoned:
+		return "poisoned"
+	case HitSnake:
+		return "hit snake"
+	}
+	return "unknown"
+}
+
+// FoodEaten is published when the snake eats the food
+type FoodEaten struct {
+	Position vars.Point // Where the food was
+	Score    int        // The score after eating it
+	Kind     string     // The kind of the food
+	Player   int        // The player whose snake ate it
+}
+
+// BonusEaten is published when the snake eats the bonus food before it disappears
+type BonusEaten struct {
+	Position vars.Point // Where the bonus was
+	Points   int        // The points it was worth
+	Score    int        // The score after eating it
+	Player   int        // The player whose snake ate it
+}
+
+// SnakeDied is published when the snake dies and the game is over
+type SnakeDied struct {
+	Cause    DeathCause // What killed the snake
+	Position vars.Point // Where the head of the snake was
+	Score    int        // The final score
+	Player   int        // The player whose snake died
+}
+
+// GameWon is published when the player reaches the winning score
+type GameWon struct {
+	Score  int // The final score
+	Player int // The player who won
+}
+
+// Paused is published when the player pauses the game
+type Paused struct{}
+
+// Resumed is published when the player resumes a paused game
+type Resumed struct{}
+
+// Restarted is published when a new game starts over the previous one
+type Restarted struct {
+	Seed int64 // The seed of the new game
+}
+
+// ScoreEntered is published when the player has typed their name for the high scores
+type ScoreEntered struct {
+	Name  string // The name of the player
+	Score int    // The score achieved by the player
+	Proof string // The path of the replay proving the score, empty if there is none
+}
+
+func (FoodEaten) event()    {}
+func (BonusEaten) event()   {}
+func (SnakeDied) event()    {}
+func (GameWon) event()      {}
+func (Paused) event()       {}
+func (Resumed) event()      {}
+func (Restarted) event()    {}
+func (ScoreEntered) event() {}
@@ -1,20 +1,115 @@
+// Package food places the food on the board and describes the kinds of food a game can have
 package food
 
 import (
+	"time"
+
+	"GoSnake/rng"
 	"GoSnake/vars"
-	"math/rand"
 )
 
+// Normal is the kind of the classic food, which is the only one unless the rules list others
+const Normal = "normal"
+
 type Food struct {
-	Position vars.Point
+	Position vars.Point `json:"position"`
+	Kind     string     `json:"kind"`              // The kind of the food, one of the types of the rules
+	Expires  int        `json:"expires,omitempty"` // The tick a deadly food disappears on, 0 for the food the snakes look for
+}
+
+// Type describes a kind of food and what eating it does
+type Type struct {
+	Kind     string        `json:"kind"`     // The name of the kind, used to pick its color and sound
+	Weight   int           `json:"weight"`   // How often it appears compared to the other kinds
+	Points   int           `json:"points"`   // The points it's worth, the score never goes below zero
+	Grow     int           `json:"grow"`     // The segments the snake gains, or loses when negative
+	Interval time.Duration `json:"interval"` // How much the time between two moves changes, negative speeds the snake up
+	Deadly   bool          `json:"deadly"`   // Whether eating it kills the snake
+	Lifetime int           `json:"lifetime"` // The number of moves a deadly food stays on the board, 0 for the default
+}
+
+func NewFood(r *rng.Rand, board vars.Board) *Food {
+	f := &Food{}
+	f.Reset(r, board)
+	return f
+}
+
+// Reset moves the food to a random cell of the board, every cell is reachable whether the board wraps or not
+func (f *Food) Reset(r *rng.Rand, board vars.Board) {
+	f.Position = vars.Point{X: r.Intn(board.Width), Y: r.Intn(board.Height)}
+}
+
+// ResetWhere moves the food to a random cell of the board where allowed returns true. It returns false and
+// leaves the food where it was when there's no such cell
+func (f *Food) ResetWhere(r *rng.Rand, board vars.Board, allowed func(vars.Point) bool) bool {
+	position := f.Position
+	cells := board.Width * board.Height
+	f.Reset(r, board)
+	for tries := 1; !allowed(f.Position); tries++ {
+		// Only look for a free cell once in a while, so the board is rarely scanned and the random draws stay
+		// the same as when there's one
+		if tries%cells == 0 && !anyAllowed(board, allowed) {
+			f.Position = position
+			return false
+		}
+		f.Reset(r, board)
+	}
+	return true
 }
 
-func NewFood() *Food {
-	return &Food{
-		Position: vars.Point{X: rand.Intn(vars.ScreenWidth / vars.TileSize), Y: rand.Intn(vars.ScreenHeight / vars.TileSize)},
+// anyAllowed reports whether allowed returns true for a cell of the board
+func anyAllowed(board vars.Board, allowed func(vars.Point) bool) bool {
+	for y := 0; y < board.Height; y++ {
+		for x := 0; x < board.Width; x++ {
+			if allowed(vars.Point{X: x, Y: y}) {
+				return true
+			}
+		}
 	}
+	return false
 }
 
-func (f *Food) Reset() {
-	f.Position = vars.Point{X: rand.Intn(vars.ScreenWidth / vars.TileSize), Y: rand.Intn(vars.ScreenHeight / vars.TileSize)}
+// Pick chooses a type at random according to their weights. A single type is returned without drawing
+// a number, so games with only the classic food play the same as before food types existed
+func Pick(r *rng.Rand, types []Type) Type {
+	if len(types) == 1 {
+		return types[0]
+	}
+	total := 0
+	for _, t := range types {
+		total += t.Weight
+	}
+	n := r.Intn(total)
+	for _, t := range types {
+		if n < t.Weight {
+			return t
+		}
+		n -= t.Weight
+	}
+	return types[len(types)-1]
+}
+
+// Bonus is food that appears for a limited time, worth more the sooner it's eaten
+type Bonus struct {
+	Position vars.Point `json:"position"` // Where the bonus is
+	Spawned  int        `json:"spawned"`  // The tick it appeared on
+	Expires  int        `json:"expires"`  // The tick it disappears on
+	Active   bool       `json:"active"`   // Whether the bonus is on the board
+}
+
+// Remaining returns the number of ticks left before the bonus disappears
+func (b Bonus) Remaining(tick int) int {
+	if !b.Active {
+		return 0
+	}
+	return max(b.Expires-tick, 0)
+}
+
+// Points returns the points the bonus is worth at a tick, from the maximum when it appears down to 1
+func (b Bonus) Points(tick, maxPoints int) int {
+	lifetime := b.Expires - b.Spawned
+	if lifetime <= 0 {
+		return 1
+	}
+	return max((maxPoints*b.Remaining(tick)+lifetime-1)/lifetime, 1)
 }
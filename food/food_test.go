@@ -0,0 +1,62 @@
+package food
+
+import (
+	"testing"
+
+	"GoSnake/rng"
+	"GoSnake/vars"
+)
+
+func TestPick(t *testing.T) {
+	// A single type never draws a number, so the classic game doesn't change
+	r := rng.New(1)
+	before := *r
+	if got := Pick(r, []Type{{Kind: Normal, Weight: 1}}); got.Kind != Normal || *r != before {
+		t.Errorf("picked %+v and drew from the source, want the only type without drawing", got)
+	}
+
+	types := []Type{{Kind: "common", Weight: 3}, {Kind: "rare", Weight: 1}}
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		counts[Pick(r, types).Kind]++
+	}
+	if counts["common"] < 2700 || counts["common"] > 3300 || counts["common"]+counts["rare"] != 4000 {
+		t.Errorf("picked %v out of 4000, want about 3 common for 1 rare", counts)
+	}
+}
+
+func TestBonusPoints(t *testing.T) {
+	b := Bonus{Spawned: 10, Expires: 20, Active: true}
+	tests := []struct {
+		tick      int
+		remaining int
+		points    int
+	}{
+		{10, 10, 6},
+		{11, 9, 6},
+		{15, 5, 3},
+		{19, 1, 1},
+		{20, 0, 1},
+		{25, 0, 1},
+	}
+	for _, tt := range tests {
+		if remaining, points := b.Remaining(tt.tick), b.Points(tt.tick, 6); remaining != tt.remaining || points != tt.points {
+			t.Errorf("tick %d: %d ticks left worth %d points, want %d and %d", tt.tick, remaining, points, tt.remaining, tt.points)
+		}
+	}
+	if remaining := (Bonus{Expires: 20}).Remaining(10); remaining != 0 {
+		t.Errorf("a bonus off the board has %d ticks left", remaining)
+	}
+}
+
+func TestResetWhere(t *testing.T) {
+	board := vars.Board{Width: 4, Height: 3}
+	only := vars.Point{X: 2, Y: 1}
+	f := Food{Position: vars.Point{X: 0, Y: 0}}
+	if ok := f.ResetWhere(rng.New(1), board, func(p vars.Point) bool { return p == only }); !ok || f.Position != only {
+		t.Errorf("got %v and %v, want the only allowed cell %v", ok, f.Position, only)
+	}
+	if ok := f.ResetWhere(rng.New(1), board, func(vars.Point) bool { return false }); ok || f.Position != only {
+		t.Errorf("got %v and %v with no cell allowed, want false and the food left on %v", ok, f.Position, only)
+	}
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"GoSnake/control"
+	"GoSnake/vars"
+
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+// poller is a controller reading the keyboard or the gamepads, it's polled on every frame so no press is missed
+type poller interface {
+	poll()
+}
+
+// inputController turns a snake with keys or buttons, the directions pressed between two moves are all kept
+type inputController struct {
+	justPressed func(Action) bool // Whether an action was triggered during this frame
+	turns       []vars.Point      // The directions pressed since the last move
+}
+
+// poll remembers the direction pressed during this frame, if any
+func (c *inputController) poll() {
+	if direction, ok := readDirection(c.justPressed); ok {
+		c.turns = append(c.turns, direction)
+	}
+}
+
+// Turns returns the directions pressed since the last move
+func (c *inputController) Turns(control.View) []vars.Point {
+	turns := c.turns
+	c.turns = nil
+	return turns
+}
+
+// humanController turns a snake with whatever its player presses, on the keyboard or a gamepad
+type humanController []*inputController
+
+func (c humanController) poll() {
+	for _, input := range c {
+		input.poll()
+	}
+}
+
+// Turns returns the directions pressed since the last move, on every device
+func (c humanController) Turns(view control.View) []vars.Point {
+	var turns []vars.Point
+	for _, input := range c {
+		turns = append(turns, input.Turns(view)...)
+	}
+	return turns
+}
+
+// newKeyboardController returns the controller turning the snake of a player with their keys. When players
+// share the keyboard the first one leaves the second one's keys alone, the others have no keys
+func (g *Game) newKeyboardController(player int, shared bool) *inputController {
+	justPressed := func(action Action) bool {
+		switch {
+		case !shared:
+			return g.bindings.JustPressed(action)
+		case player == 1:
+			return g.rival.JustPressed(action)
+		case player > 1:
+			return false
+		}
+		for _, key := range g.bindings[action] {
+			if inpututil.IsKeyJustPressed(key) && len(g.rival.ActionsOf(key)) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	return &inputController{justPressed: justPressed}
+}
+
+// newGamepadController returns the controller turning the snake of a player with their gamepad, or with any
+// gamepad when the player is alone
+func (g *Game) newGamepadController(player int, shared bool) *inputController {
+	justPressed := func(action Action) bool {
+		if !shared {
+			return g.gamepads.AnyJustPressed(action)
+		}
+		return g.gamepads.JustPressed(player, action)
+	}
+	return &inputController{justPressed: justPressed}
+}
+
+// newControllers returns the controller of each snake of a game: the replay being played, the bots,
+// or the players at the keyboard and the gamepads
+func (g *Game) newControllers(players int) []control.Controller {
+	if g.player != nil {
+		return control.Replay(g.player, players)
+	}
+	controllers := make([]control.Controller, players)
+	for i := range controllers {
+		if i < len(g.bots) && g.bots[i] != nil {
+			controllers[i] = control.PerMove(g.bots[i])
+			continue
+		}
+		controllers[i] = humanController{g.newKeyboardController(i, players > 1), g.newGamepadController(i, players > 1)}
+	}
+	return controllers
+}
+
+// poll reads the keyboard and the gamepads for the controllers of the players
+func (g *Game) poll() {
+	for _, c := range g.controllers {
+		if p, ok := c.(poller); ok {
+			p.poll()
+		}
+	}
+}
+
+// human reports whether a player's snake is steered by a person rather than a bot
+func (g *Game) human(player int) bool {
+	return player >= len(g.bots) || g.bots[player] == nil
+}
@@ -1,21 +1,36 @@
 package game
 
 import (
-	"GoSnake/food"
-	"GoSnake/sound"
-	"GoSnake/vars"
+	"log"
+	"time"
+
+	"GoSnake/control"
+	"GoSnake/engine"
+	"GoSnake/event"
+	"GoSnake/netplay"
+	"GoSnake/replay"
 
 	"github.com/hajimehoshi/ebiten"
 )
 
 type Game struct {
-	snake        *Snake
-	food         *food.Food
-	renderer     *Renderer
-	logic        *GameLogic
-	startManager *GameStartManager
-	pauseManager *GamePauseManager
-	audioManager *sound.AudioManager
+	rules       engine.Rules // The rules new games are played with
+	bindings    Bindings     // The keys bound to each action
+	rival       Bindings     // The keys turning the second player's snake
+	gamepads    *Gamepads    // The gamepads and the players they're assigned to
+	settings    string       // The path of the configuration file where changed controls are saved
+	state       engine.State
+	controllers []control.Controller // The controller of each snake of the game
+	bots        []control.Controller // The bot playing each player, nil for the players at the keyboard
+	renderer    *Renderer
+	logic       *GameLogic
+	bus         *event.Bus
+	quit        bool                 // Set when the player asked to leave the game
+	recording   *replay.Replay       // The turns of the current game, nil when it can't be replayed
+	player      *replay.Player       // Plays a replay instead of reading the keyboard, nil when the player is playing
+	proof       string               // The path of the replay of the last finished game
+	client      *netplay.Client      // Plays on a server instead of running the engine, nil when the game runs here
+	broadcaster *netplay.Broadcaster // Shows the game to spectators, nil when it isn't broadcast
 }
 
 type Drawable interface {
@@ -26,34 +41,136 @@ type Updatable interface {
 	Update() error
 }
 
-func NewGame(snake *Snake, food *food.Food, renderer *Renderer, logic *GameLogic, startManager *GameStartManager, pauseManager *GamePauseManager, audioManager *sound.AudioManager) *Game {
-	return &Game{
-		snake:        snake,
-		food:         food,
-		renderer:     renderer,
-		logic:        logic,
-		startManager: startManager,
-		pauseManager: pauseManager,
-		audioManager: audioManager,
+func NewGame(rules engine.Rules, bindings, rival Bindings, gamepads *Gamepads, settings string, seed int64, renderer *Renderer, logic *GameLogic, bus *event.Bus) *Game {
+	g := &Game{
+		rules:     rules,
+		bindings:  bindings,
+		rival:     rival,
+		gamepads:  gamepads,
+		settings:  settings,
+		state:     engine.NewState(rules, seed),
+		recording: replay.New(rules, seed),
+		renderer:  renderer,
+		logic:     logic,
+		bus:       bus,
 	}
+	g.controllers = g.newControllers(rules.PlayerCount())
+	return g
+}
+
+// SetBots lets bots play instead of the players, the bot of each player or nil for a player at the keyboard
+func (g *Game) SetBots(bots []control.Controller) {
+	g.bots = bots
+	g.controllers = g.newControllers(len(g.state.Snakes))
 }
 
+// Draw draws the board and the score, the screens draw their own text over it
 func (g *Game) Draw(screen *ebiten.Image) {
 	g.renderer.screen = screen
+	g.renderer.board = g.state.Rules.Board
 	g.renderer.drawBackground()
-	g.renderer.drawSnake(g.snake.Body)
-	g.renderer.drawFood(g.food.Position)
-	gm := NewGameManager(g, g.startManager, g.pauseManager)
-	g.renderer.drawUI(g.logic.score, g.logic.gameOver, g.logic.gameWon, g.startManager.IsGameStarted(), gm.gamePaused)
+	if g.state.Rules.Level != nil {
+		g.renderer.drawWalls(g.state.Rules.Level.Walls)
+	}
+	g.renderer.drawPortals(g.state.Portals)
+	for i, snake := range g.state.Snakes {
+		if !snake.Dead {
+			g.renderer.drawSnake(snake.Body, i)
+		}
+	}
+	for _, f := range g.state.Foods {
+		g.renderer.drawFood(f)
+	}
+	g.renderer.drawBonus(g.state.Bonus, g.state.Tick)
+	g.renderer.drawScore(g.state.Scores)
 }
 
 func (g *Game) Layout(_, _ int) (int, int) {
-	return vars.ScreenWidth, vars.ScreenHeight
+	return g.renderer.ScreenSize(g.state.Rules.Board)
 }
 
+// PlayReplay replaces the game with the playback of a replay
+func (g *Game) PlayReplay(r *replay.Replay) {
+	g.player = replay.NewPlayer(r)
+	g.restart()
+}
+
+// Connect plays on a server instead of running the game here
+func (g *Game) Connect(c *netplay.Client) {
+	g.client = c
+	g.recording = nil
+	g.state.Snakes = nil // Nothing to show until the server starts a game
+	g.state.Foods = nil
+}
+
+// Broadcast shows the game to the spectators of a broadcast from now on
+func (g *Game) Broadcast(b *netplay.Broadcaster) {
+	g.broadcaster = b
+	b.Start(g.state)
+}
+
+// step feeds the turns of the controllers to the engine and publishes what happened
+func (g *Game) step() {
+	var input engine.Input
+	for i, c := range g.controllers {
+		if g.state.Snakes[i].Dead {
+			continue
+		}
+		for _, direction := range c.Turns(control.NewView(g.state, i)) {
+			input.Add(i, direction)
+		}
+	}
+	if g.recording != nil {
+		g.recording.Record(g.state.Tick, input)
+	}
+
+	var events []event.Event
+	g.state, events = engine.Step(g.state, input)
+	g.bus.Publish(events...)
+	if g.broadcaster != nil {
+		g.broadcaster.Publish(g.state)
+	}
+
+	// Keep the replay of the games played by the player once they're over
+	if (g.state.GameOver || g.state.GameWon) && g.recording != nil && g.player == nil {
+		path, err := SaveReplay(g.recording)
+		if err != nil {
+			log.Printf("Error saving replay: %v", err)
+		} else {
+			log.Printf("Replay saved to %s", path)
+			g.proof = path
+		}
+		g.recording = nil
+	}
+}
+
+// restart starts a new game with a fresh seed, or plays the replay again from the start
 func (g *Game) restart() {
-	g.snake = NewSnake()
-	g.food = food.NewFood()
-	g.logic = NewGameLogic(g.audioManager) // Use the existing audioManager
-	g.logic.restartGame()                  // Ensure the game logic is correctly reset
+	rules, seed := g.rules, time.Now().UnixNano()
+	if g.player != nil {
+		rules = g.player.Rules()
+		seed = g.player.Seed()
+		g.player.Rewind()
+	}
+	g.state = engine.NewState(rules, seed)
+	g.controllers = g.newControllers(rules.PlayerCount())
+	g.logic = NewGameLogic()
+	g.recording = replay.New(rules, seed)
+	g.proof = ""
+	g.bus.Publish(event.Restarted{Seed: g.state.Seed})
+	if g.broadcaster != nil {
+		g.broadcaster.Start(g.state)
+	}
+}
+
+// resume replaces the current game with a saved one, a nil recording means it can't be replayed
+func (g *Game) resume(state engine.State, recording *replay.Replay) {
+	g.state = state
+	g.recording = recording
+	g.proof = ""
+	g.controllers = g.newControllers(len(state.Snakes))
+	g.logic = NewGameLogic()
+	if g.broadcaster != nil {
+		g.broadcaster.Start(g.state)
+	}
 }
@@ -1,79 +1,85 @@
 package game
 
 import (
-	"GoSnake/vars"
+	"errors"
+
+	"GoSnake/save"
 
 	"github.com/hajimehoshi/ebiten"
-	"github.com/hajimehoshi/ebiten/inpututil"
 )
 
-// GameManager manages the game state and user input
+// ErrQuit is returned by Update when the player quits from the menu
+var ErrQuit = errors.New("quit")
+
+// GameManager adapts the game to ebiten by running its state machine
 type GameManager struct {
-	game         *Game
-	startManager *GameStartManager // Manages the game start state
-	pauseManager *GamePauseManager // Manages the game pause state
-	gamePaused   bool              // Indicates if the game is paused
+	game    *Game
+	machine *StateMachine // Switches between the title, playing, paused... screens
 }
 
-// NewGameManager creates a new GameManager object
-func NewGameManager(game *Game, startManager *GameStartManager, pauseManager *GamePauseManager) *GameManager {
-	return &GameManager{game: game, startManager: startManager, pauseManager: pauseManager}
+// NewGameManager creates a new GameManager object showing the title screen, or the game of the server
+// the game is connected to
+func NewGameManager(game *Game) *GameManager {
+	initial := TitleScreen
+	if game.client != nil {
+		initial = NetworkScreen
+	}
+	machine := NewStateMachine(map[ScreenID]Screen{
+		TitleScreen:     &titleScreen{},
+		CountdownScreen: &countdownScreen{},
+		PlayingScreen:   &playingScreen{},
+		PausedScreen:    &pausedScreen{},
+		GameOverScreen:  &endScreen{id: GameOverScreen},
+		WonScreen:       &endScreen{id: WonScreen},
+		NameEntryScreen: &nameEntryScreen{},
+		MenuScreen:      &menuScreen{},
+		ControlsScreen:  &controlsScreen{},
+		EditorScreen:    &editorScreen{},
+		NetworkScreen:   &networkScreen{},
+	}, initial)
+	machine.Start(game)
+	return &GameManager{game: game, machine: machine}
 }
 
-// Update updates the game state and handles user input
+// Update reads the gamepads and updates the current screen
 func (gm *GameManager) Update(screen *ebiten.Image) error {
-	// If the game has not started, handle start input
-	if !gm.startManager.IsGameStarted() {
-		gm.startManager.HandleStartInput()
-		return nil
+	gm.game.gamepads.Update()
+	if err := gm.machine.Update(gm.game); err != nil {
+		return err
 	}
-
-	// If the 'R' key is pressed, restart the game
-	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
-		gm.game.restart()
+	if gm.game.quit {
+		return ErrQuit
 	}
+	return nil
+}
 
-	// Handle pause input only if the game is not over
-	if !gm.game.logic.gameOver {
-		gm.pauseManager.HandlePauseInput()
-		gm.gamePaused = gm.pauseManager.IsGamePaused()
+// Close saves the game if it's still in progress, so it can be continued on the next launch,
+// or disconnects from the server. The spectators are disconnected
+func (gm *GameManager) Close() error {
+	if gm.game.broadcaster != nil {
+		gm.game.broadcaster.Close()
 	}
-
-	// If the game is paused or over, return
-	if gm.gamePaused || gm.game.logic.gameOver {
-		return nil
+	if gm.game.client != nil {
+		return gm.game.client.Close()
 	}
-
-	// Handle game state and restart if necessary
-	if gm.game.logic.HandleGameState(inpututil.IsKeyJustPressed(ebiten.KeyR), gm.startManager.IsGameStarted()) {
+	if gm.game.state.GameOver || gm.game.state.GameWon || gm.game.player != nil {
 		return nil
 	}
-
-	// Process input for the snake direction
-	gm.game.snake.processInput()
-
-	// Update the game logic and check for collisions
-	if gm.game.logic.UpdateTick() {
-		gm.game.snake.updateDirection()
-		gm.game.logic.CheckCollisions(gm.game.snake, gm.game.food)
+	switch gm.machine.Current() {
+	case CountdownScreen, PlayingScreen, PausedScreen, MenuScreen:
+		return save.Write(save.Path, gm.game.state, gm.game.recording)
 	}
-
-	// Draw the game
-	gm.game.Draw(screen)
-
 	return nil
 }
 
-// Draw draws the game and UI
+// Draw draws the game and the current screen
 func (gm *GameManager) Draw(screen *ebiten.Image) {
-	// Draw the game
 	gm.game.Draw(screen)
-	// Draw the UI
-	gm.game.renderer.drawUI(gm.game.logic.score, gm.game.logic.gameOver, gm.game.logic.gameWon, gm.startManager.IsGameStarted(), gm.gamePaused)
+	gm.machine.Draw(gm.game)
 }
 
 // Layout returns the screen width and height
 func (gm *GameManager) Layout(outsideWidth, outsideHeight int) (int, int) {
 	// Return the screen dimensions
-	return vars.ScreenWidth, vars.ScreenHeight
+	return gm.game.Layout(outsideWidth, outsideHeight)
 }
@@ -0,0 +1,155 @@
+package game
+
+import (
+	"log"
+	"math"
+
+	"GoSnake/config"
+	"GoSnake/vars"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+// MaxPlayers is the number of people who can play on one machine, each with their keys and a gamepad
+const MaxPlayers = 2
+
+// Gamepads tracks the connected gamepads and assigns each one to a player, a gamepad plugged in
+// takes the first player without one and frees it when it's unplugged
+type Gamepads struct {
+	settings config.Gamepad     // The buttons bound to each action and the stick settings
+	players  [MaxPlayers]int    // The gamepad of each player, -1 when the player has none
+	sticks   map[int]vars.Point // The direction each stick points to during this frame
+	previous map[int]vars.Point // The direction each stick pointed to during the previous frame
+}
+
+// NewGamepads creates a Gamepads object with no gamepad assigned yet
+func NewGamepads(settings config.Gamepad) *Gamepads {
+	gp := &Gamepads{settings: settings, sticks: map[int]vars.Point{}, previous: map[int]vars.Point{}}
+	for i := range gp.players {
+		gp.players[i] = -1
+	}
+	return gp
+}
+
+// Update assigns the gamepads plugged in since the last frame and reads the sticks, it must be called once per frame
+func (gp *Gamepads) Update() {
+	connected := map[int]bool{}
+	for _, id := range ebiten.GamepadIDs() {
+		connected[id] = true
+	}
+
+	// Free the players whose gamepad was unplugged
+	for player, id := range gp.players {
+		if id >= 0 && (!connected[id] || inpututil.IsGamepadJustDisconnected(id)) {
+			log.Printf("Gamepad %d disconnected from player %d", id, player+1)
+			gp.players[player] = -1
+			delete(gp.sticks, id)
+			delete(gp.previous, id)
+		}
+	}
+
+	// Give the new gamepads to the players without one
+	for _, id := range ebiten.GamepadIDs() {
+		if gp.Player(id) >= 0 {
+			continue
+		}
+		for player := range gp.players {
+			if gp.players[player] < 0 {
+				log.Printf("Gamepad %d (%s) assigned to player %d", id, ebiten.GamepadName(id), player+1)
+				gp.players[player] = id
+				break
+			}
+		}
+	}
+
+	// Remember where the sticks pointed so a turn only fires when the stick moves to a new direction
+	for _, id := range gp.players {
+		if id >= 0 {
+			gp.previous[id] = gp.sticks[id]
+			gp.sticks[id] = gp.readStick(id)
+		}
+	}
+}
+
+// Player returns the player a gamepad is assigned to, -1 if it isn't assigned
+func (gp *Gamepads) Player(id int) int {
+	for player, assigned := range gp.players {
+		if assigned == id {
+			return player
+		}
+	}
+	return -1
+}
+
+// JustPressed reports whether the gamepad of a player triggered an action during this frame
+func (gp *Gamepads) JustPressed(player int, action Action) bool {
+	if player < 0 || player >= MaxPlayers || gp.players[player] < 0 {
+		return false
+	}
+	id := gp.players[player]
+
+	for _, button := range *gp.settings.Buttons(action.String()) {
+		if button < ebiten.GamepadButtonNum(id) && inpututil.IsGamepadButtonJustPressed(id, ebiten.GamepadButton(button)) {
+			return true
+		}
+	}
+
+	// The stick turns when it moves to a new direction, holding it doesn't repeat the turn
+	stick := gp.sticks[id]
+	if stick == gp.previous[id] {
+		return false
+	}
+	switch action {
+	case ActionUp:
+		return stick == vars.Point{X: 0, Y: -1}
+	case ActionDown:
+		return stick == vars.Point{X: 0, Y: 1}
+	case ActionLeft:
+		return stick == vars.Point{X: -1, Y: 0}
+	case ActionRight:
+		return stick == vars.Point{X: 1, Y: 0}
+	}
+	return false
+}
+
+// AnyJustPressed reports whether any assigned gamepad triggered an action during this frame
+func (gp *Gamepads) AnyJustPressed(action Action) bool {
+	for player := range gp.players {
+		if gp.JustPressed(player, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// readStick returns the direction the stick of a gamepad points to, snapped to one of the four
+// directions, or no direction while it's inside the dead zone
+func (gp *Gamepads) readStick(id int) vars.Point {
+	axes := ebiten.GamepadAxisNum(id)
+	if gp.settings.StickX >= axes || gp.settings.StickY >= axes {
+		return vars.Point{}
+	}
+	x := ebiten.GamepadAxis(id, gp.settings.StickX)
+	y := ebiten.GamepadAxis(id, gp.settings.StickY)
+	return snapStick(x, y, gp.settings.DeadZone)
+}
+
+// snapStick snaps the position of a stick to the direction of its largest axis, the dead zone is
+// a circle around the center so diagonals need the same push as straight directions. A stick at rest
+// never turns the snake, even without a dead zone
+func snapStick(x, y, deadZone float64) vars.Point {
+	if math.Hypot(x, y) <= deadZone {
+		return vars.Point{}
+	}
+	if math.Abs(x) > math.Abs(y) {
+		if x < 0 {
+			return vars.Point{X: -1, Y: 0}
+		}
+		return vars.Point{X: 1, Y: 0}
+	}
+	if y < 0 {
+		return vars.Point{X: 0, Y: -1}
+	}
+	return vars.Point{X: 0, Y: 1}
+}
@@ -0,0 +1,34 @@
+package game
+
+import (
+	"testing"
+
+	"GoSnake/vars"
+)
+
+func TestSnapStick(t *testing.T) {
+	tests := []struct {
+		name     string
+		x, y     float64
+		deadZone float64
+		want     vars.Point
+	}{
+		{"at rest", 0, 0, 0.5, vars.Point{}},
+		{"at rest without a dead zone", 0, 0, 0, vars.Point{}},
+		{"inside the dead zone", 0.3, -0.3, 0.5, vars.Point{}},
+		{"on the edge of the dead zone", 0.5, 0, 0.5, vars.Point{}},
+		{"slightly pushed without a dead zone", 0.01, 0, 0, vars.Point{X: 1, Y: 0}},
+		{"left", -0.9, 0.2, 0.5, vars.Point{X: -1, Y: 0}},
+		{"right", 0.9, -0.2, 0.5, vars.Point{X: 1, Y: 0}},
+		{"up", 0.2, -0.9, 0.5, vars.Point{X: 0, Y: -1}},
+		{"down", -0.2, 0.9, 0.5, vars.Point{X: 0, Y: 1}},
+		{"diagonal out of the dead zone", 0.4, 0.4, 0.5, vars.Point{X: 0, Y: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snapStick(tt.x, tt.y, tt.deadZone); got != tt.want {
+				t.Errorf("snapStick(%v, %v, %v) = %v, want %v", tt.x, tt.y, tt.deadZone, got, tt.want)
+			}
+		})
+	}
+}
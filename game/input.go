@@ -0,0 +1,159 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"GoSnake/config"
+	"GoSnake/vars"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+// Action is something the player can do, whatever key it's bound to
+type Action int
+
+const (
+	ActionUp      Action = iota // Turn up
+	ActionDown                  // Turn down
+	ActionLeft                  // Turn left
+	ActionRight                 // Turn right
+	ActionPause                 // Pause and resume the game
+	ActionStart                 // Start the game from the title screen
+	ActionRestart               // Restart the game
+	ActionMenu                  // Open and close the menu
+)
+
+// Actions lists every action, in the order of config.Actions
+var Actions = []Action{ActionUp, ActionDown, ActionLeft, ActionRight, ActionPause, ActionStart, ActionRestart, ActionMenu}
+
+// String returns the name of the action as written in the configuration
+func (a Action) String() string {
+	if int(a) < len(config.Actions) {
+		return config.Actions[a]
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// Bindings maps each action to the keys triggering it, an action can have several keys
+type Bindings map[Action][]ebiten.Key
+
+// NewBindings looks up the keys named in the controls settings
+func NewBindings(controls config.Controls) (Bindings, error) {
+	bindings := Bindings{}
+	for _, action := range Actions {
+		for _, name := range *controls.Keys(action.String()) {
+			key, ok := keyByName(name)
+			if !ok {
+				return nil, fmt.Errorf("controls.%s: unknown key %q", action, name)
+			}
+			bindings.Bind(action, key)
+		}
+	}
+	return bindings, nil
+}
+
+// Controls returns the bindings as written in the configuration
+func (b Bindings) Controls() config.Controls {
+	var controls config.Controls
+	for _, action := range Actions {
+		names := []string{}
+		for _, key := range b[action] {
+			names = append(names, key.String())
+		}
+		*controls.Keys(action.String()) = names
+	}
+	return controls
+}
+
+// Bind adds a key to an action, unless it's already bound to it
+func (b Bindings) Bind(action Action, key ebiten.Key) {
+	for _, bound := range b[action] {
+		if bound == key {
+			return
+		}
+	}
+	b[action] = append(b[action], key)
+}
+
+// Unbind removes a key from an action
+func (b Bindings) Unbind(action Action, key ebiten.Key) {
+	keys := b[action][:0:0]
+	for _, bound := range b[action] {
+		if bound != key {
+			keys = append(keys, bound)
+		}
+	}
+	b[action] = keys
+}
+
+// ActionsOf returns the actions a key is bound to
+func (b Bindings) ActionsOf(key ebiten.Key) []Action {
+	var actions []Action
+	for _, action := range Actions {
+		for _, bound := range b[action] {
+			if bound == key {
+				actions = append(actions, action)
+			}
+		}
+	}
+	return actions
+}
+
+// JustPressed reports whether one of the keys of an action was pressed during this frame
+func (b Bindings) JustPressed(action Action) bool {
+	for _, key := range b[action] {
+		if inpututil.IsKeyJustPressed(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns the name of the first key of an action, as shown in the instructions
+func (b Bindings) Name(action Action) string {
+	if len(b[action]) == 0 {
+		return "?"
+	}
+	return strings.ToUpper(b[action][0].String())
+}
+
+// keyByName returns the key with the given name, ignoring case
+func keyByName(name string) (ebiten.Key, bool) {
+	for key := ebiten.Key(0); key <= ebiten.KeyMax; key++ {
+		if strings.EqualFold(key.String(), name) {
+			return key, true
+		}
+	}
+	return 0, false
+}
+
+// justPressedKey returns the key pressed during this frame, if any
+func justPressedKey() (ebiten.Key, bool) {
+	for key := ebiten.Key(0); key <= ebiten.KeyMax; key++ {
+		if inpututil.IsKeyJustPressed(key) {
+			return key, true
+		}
+	}
+	return 0, false
+}
+
+// justPressed reports whether an action was triggered during this frame, with the keyboard or any gamepad
+func (g *Game) justPressed(action Action) bool {
+	return g.bindings.JustPressed(action) || g.gamepads.AnyJustPressed(action)
+}
+
+// readDirection returns the direction requested during this frame, if any
+func readDirection(justPressed func(Action) bool) (vars.Point, bool) {
+	if justPressed(ActionLeft) {
+		return vars.Point{X: -1, Y: 0}, true // Move left
+	} else if justPressed(ActionRight) {
+		return vars.Point{X: 1, Y: 0}, true // Move right
+	} else if justPressed(ActionUp) {
+		return vars.Point{X: 0, Y: -1}, true // Move up
+	} else if justPressed(ActionDown) {
+		return vars.Point{X: 0, Y: 1}, true // Move down
+	}
+	return vars.Point{}, false
+}
@@ -4,7 +4,12 @@ import (
 	"fmt"
 	"image/color"
 	"log"
+	"strings"
 
+	"GoSnake/config"
+	"GoSnake/engine"
+	"GoSnake/food"
+	"GoSnake/level"
 	"GoSnake/vars"
 
 	"github.com/hajimehoshi/ebiten"
@@ -16,105 +21,316 @@ import (
 
 // Renderer handles rendering the game
 type Renderer struct {
-	screen *ebiten.Image // The screen image to render on
-	face   font.Face     // The font face to use for rendering text
+	screen  *ebiten.Image  // The screen image to render on
+	board   vars.Board     // The board being rendered
+	face    font.Face      // The font face to use for rendering text
+	visuals config.Visuals // The colors to render with
 }
 
-// NewRenderer creates a new Renderer instance
-func NewRenderer() *Renderer {
+// NewRenderer creates a new Renderer instance using the colors of the visual settings
+func NewRenderer(visuals config.Visuals) *Renderer {
 	return &Renderer{
-		face: basicfont.Face7x13, // Using a basic font face
+		face:    basicfont.Face7x13, // Using a basic font face
+		visuals: visuals,
 	}
 }
 
-// drawBackground fills the screen with a green color
+// ScreenSize returns the size of a board on screen, in pixels
+func (r *Renderer) ScreenSize(board vars.Board) (width, height int) {
+	return board.Width * r.visuals.TileSize, board.Height * r.visuals.TileSize
+}
+
+// screenWidth returns the width of the board being drawn, in pixels
+func (r *Renderer) screenWidth() int {
+	width, _ := r.ScreenSize(r.board)
+	return width
+}
+
+// screenHeight returns the height of the board being drawn, in pixels
+func (r *Renderer) screenHeight() int {
+	_, height := r.ScreenSize(r.board)
+	return height
+}
+
+// cellAt returns the cell of the board under a point of the screen
+func (r *Renderer) cellAt(x, y int) vars.Point {
+	return vars.Point{X: x / r.visuals.TileSize, Y: y / r.visuals.TileSize}
+}
+
+// drawBackground fills the screen with the background color
 func (r *Renderer) drawBackground() {
-	r.screen.Fill(color.RGBA{154, 198, 0, 255})
+	r.screen.Fill(r.visuals.Background.ToRGBA())
 }
 
-// drawSnake draws the snake's body on the screen
-func (r *Renderer) drawSnake(body []vars.Point) {
+// drawSnake draws the body of a player's snake on the screen, in the player's color
+func (r *Renderer) drawSnake(body []vars.Point, player int) {
+	clr := r.visuals.Snake
+	if player > 0 && player <= len(r.visuals.Snakes) {
+		clr = r.visuals.Snakes[player-1]
+	}
 	for _, p := range body {
-		ebitenutil.DrawRect(r.screen, float64(p.X*vars.TileSize), float64(p.Y*vars.TileSize), vars.TileSize, vars.TileSize, color.RGBA{33, 50, 15, 255})
-	}
-}
-
-// drawFood draws the food on the screen
-func (r *Renderer) drawFood(position vars.Point) {
-	ebitenutil.DrawRect(r.screen, float64(position.X*vars.TileSize), float64(position.Y*vars.TileSize), vars.TileSize, vars.TileSize, color.RGBA{231, 71, 29, 255})
-}
-
-// drawUI draws the user interface elements on the screen
-func (r *Renderer) drawUI(score int, gameOver bool, gameWon bool, gameStarted bool, gamePaused bool) {
-	// Draw the score
-	scoreText := fmt.Sprintf("Score: %d", score)
-	text.Draw(r.screen, scoreText, r.face, 5, vars.ScreenHeight-5, color.White)
-
-	// Draw the start game text if the game has not started
-	if !gameStarted {
-		startText := "Press 'SPACE' to start the game"
-		startTextWidth := text.BoundString(r.face, startText).Dx()
-		x := (vars.ScreenWidth - startTextWidth) / 2
-		text.Draw(r.screen, startText, r.face, x, vars.ScreenHeight/2, color.White)
-	} else {
-		// Draw game over text and restart instructions if the game is over
-		if gameOver {
-			// Draw game over text
-			gameOverText := "Game Over"
-			gameOverTextWidth := text.BoundString(r.face, gameOverText).Dx()
-			x := (vars.ScreenWidth - gameOverTextWidth) / 2
-			text.Draw(r.screen, gameOverText, r.face, x, vars.ScreenHeight/2, color.White)
-
-			// Draw restart instructions
-			restartText := "Press 'R' to restart"
-			restartTextWidth := text.BoundString(r.face, restartText).Dx()
-			x = (vars.ScreenWidth - restartTextWidth) / 2
-			text.Draw(r.screen, restartText, r.face, x, vars.ScreenHeight/2+16, color.White)
-
-			// Draw the high scores
-			scores, err := LoadScores()
-			if err == nil {
-				startY := vars.ScreenHeight/2 + 32
-				for i, entry := range scores {
-					if i >= 5 {
-						break
-					}
-					scoreLine := fmt.Sprintf("%d. %s: %d", i+1, entry.Name, entry.Score)
-					text.Draw(r.screen, scoreLine, r.face, vars.ScreenWidth/2-60, startY+(i*16), color.White)
-				}
-			} else {
-				log.Printf("Error loading scores: %v", err)
+		r.drawTile(p, clr.ToRGBA())
+	}
+}
+
+// drawFood draws the food on the screen, in the color of its kind
+func (r *Renderer) drawFood(f food.Food) {
+	clr, ok := r.visuals.Foods[f.Kind]
+	if !ok {
+		clr = r.visuals.Food
+	}
+	r.drawTile(f.Position, clr.ToRGBA())
+}
+
+// drawBonus draws the bonus food and, along the top of the board, a bar shrinking until it disappears
+func (r *Renderer) drawBonus(b food.Bonus, tick int) {
+	if !b.Active {
+		return
+	}
+	r.drawTile(b.Position, r.visuals.Bonus.ToRGBA())
+	width := float64(r.screenWidth()*b.Remaining(tick)) / float64(max(b.Expires-b.Spawned, 1))
+	ebitenutil.DrawRect(r.screen, 0, 0, width, 2, r.visuals.Bonus.ToRGBA())
+}
+
+// drawPortals draws each end of the portals as a ring, with the digit of the portal next to it so the
+// two ends of a portal can be told apart from the others
+func (r *Renderer) drawPortals(portals []level.Portal) {
+	size := float64(r.visuals.TileSize)
+	inset := size / 3
+	for i, portal := range portals {
+		for _, end := range []vars.Point{portal.A, portal.B} {
+			r.drawTile(end, r.visuals.Portal.ToRGBA())
+			ebitenutil.DrawRect(r.screen, float64(end.X)*size+inset, float64(end.Y)*size+inset, size-2*inset, size-2*inset, r.visuals.Background.ToRGBA())
+			if r.visuals.TileSize >= 8 {
+				text.Draw(r.screen, fmt.Sprint(i), r.face, end.X*r.visuals.TileSize, end.Y*r.visuals.TileSize, r.visuals.Text.ToRGBA())
 			}
 		}
+	}
+}
+
+// drawWalls draws the walls of a level
+func (r *Renderer) drawWalls(walls []vars.Point) {
+	for _, p := range walls {
+		r.drawTile(p, r.visuals.Wall.ToRGBA())
+	}
+}
+
+// drawTile fills a cell of the board with a color
+func (r *Renderer) drawTile(p vars.Point, clr color.Color) {
+	size := float64(r.visuals.TileSize)
+	ebitenutil.DrawRect(r.screen, float64(p.X)*size, float64(p.Y)*size, size, size, clr)
+}
 
-		// Draw game won text and restart instructions if the game is won
-		if gameWon {
-			// Draw game won text
-			gameOverText := "You Won!"
-			gameOverTextWidth := text.BoundString(r.face, gameOverText).Dx()
-			x := (vars.ScreenWidth - gameOverTextWidth) / 2
-			text.Draw(r.screen, gameOverText, r.face, x, vars.ScreenHeight/2, color.White)
-
-			// Draw restart instructions
-			restartText := "Press 'R' to restart"
-			restartTextWidth := text.BoundString(r.face, restartText).Dx()
-			x = (vars.ScreenWidth - restartTextWidth) / 2
-			text.Draw(r.screen, restartText, r.face, x, vars.ScreenHeight/2+16, color.White)
+// drawScore draws the score in the bottom left corner, or the score of each player when there are several
+func (r *Renderer) drawScore(scores []int) {
+	scoreText := fmt.Sprintf("Score: %d", scores[0])
+	if len(scores) > 1 {
+		var parts []string
+		for i, score := range scores {
+			parts = append(parts, fmt.Sprintf("P%d: %d", i+1, score))
 		}
+		scoreText = strings.Join(parts, "  ")
+	}
+	text.Draw(r.screen, scoreText, r.face, 5, r.screenHeight()-5, r.visuals.Text.ToRGBA())
+}
+
+// drawCenteredText draws a line of text centered horizontally at the given height
+func (r *Renderer) drawCenteredText(line string, y int) {
+	lineWidth := text.BoundString(r.face, line).Dx()
+	x := (r.screenWidth() - lineWidth) / 2
+	text.Draw(r.screen, line, r.face, x, y, r.visuals.Text.ToRGBA())
+}
 
-		// Draw paused game text and resume instructions if the game is paused
-		if gamePaused {
-			// Draw paused game text
-			pausedText := "You paused the game"
-			pausedTextWidth := text.BoundString(r.face, pausedText).Dx()
-			x := (vars.ScreenWidth - pausedTextWidth) / 2
-			text.Draw(r.screen, pausedText, r.face, x, vars.ScreenHeight/2-16, color.White)
-
-			// Draw resume instructions
-			resumeText := "Press 'P' to resume"
-			resumeTextWidth := text.BoundString(r.face, resumeText).Dx()
-			x = (vars.ScreenWidth - resumeTextWidth) / 2
-			text.Draw(r.screen, resumeText, r.face, x, vars.ScreenHeight/2, color.White)
+// drawTitle draws the start game text, and how to continue the saved game if there is one
+func (r *Renderer) drawTitle(startKey, menuKey string, hasSaved bool) {
+	r.drawCenteredText(fmt.Sprintf("Press '%s' to start the game", startKey), r.screenHeight()/2)
+	r.drawCenteredText(fmt.Sprintf("Press '%s' for the controls", menuKey), r.screenHeight()/2+16)
+	r.drawCenteredText("Press 'E' to edit a level", r.screenHeight()/2+32)
+	if hasSaved {
+		r.drawCenteredText("Press 'C' to continue your game", r.screenHeight()/2+48)
+	}
+}
+
+// drawCountdown draws the number of seconds left before the game starts
+func (r *Renderer) drawCountdown(secondsLeft int) {
+	r.drawCenteredText("Get ready", r.screenHeight()/2-16)
+	r.drawCenteredText(fmt.Sprintf("%d", secondsLeft), r.screenHeight()/2)
+}
+
+// drawGameOver draws game over text, restart instructions and the high scores
+func (r *Renderer) drawGameOver(seed int64, restartKey string) {
+	r.drawCenteredText("Game Over", r.screenHeight()/2)
+	r.drawCenteredText(fmt.Sprintf("Press '%s' to restart", restartKey), r.screenHeight()/2+16)
+
+	// Draw the seed so the game can be played again
+	r.drawSeed(seed)
+
+	// Draw the high scores
+	scores, err := LoadScores()
+	if err != nil {
+		log.Printf("Error loading scores: %v", err)
+		return
+	}
+	startY := r.screenHeight()/2 + 32
+	for i, entry := range scores {
+		if i >= 5 {
+			break
 		}
+		scoreLine := fmt.Sprintf("%d. %s: %d", i+1, entry.Name, entry.Score)
+		text.Draw(r.screen, scoreLine, r.face, r.screenWidth()/2-60, startY+(i*16), r.visuals.Text.ToRGBA())
 	}
 }
+
+// drawWon draws game won text and restart instructions
+func (r *Renderer) drawWon(seed int64, restartKey string) {
+	r.drawCenteredText("You Won!", r.screenHeight()/2)
+	r.drawCenteredText(fmt.Sprintf("Press '%s' to restart", restartKey), r.screenHeight()/2+16)
+
+	// Draw the seed so the game can be played again
+	r.drawSeed(seed)
+}
+
+// drawResult draws the outcome of a game between several players and their scores
+func (r *Renderer) drawResult(winner int, scores []int, seed int64, restartKey string) {
+	result := "Draw!"
+	if winner >= 0 {
+		result = fmt.Sprintf("Player %d wins!", winner+1)
+	}
+	r.drawCenteredText(result, r.screenHeight()/2)
+	r.drawCenteredText(fmt.Sprintf("Press '%s' to restart", restartKey), r.screenHeight()/2+16)
+	for i, score := range scores {
+		r.drawCenteredText(fmt.Sprintf("Player %d: %d", i+1, score), r.screenHeight()/2+32+i*16)
+	}
+
+	// Draw the seed so the game can be played again
+	r.drawSeed(seed)
+}
+
+// drawNetwork draws the state of a game played on a server over the board: the snake of the player, the result
+// of the last game, or why there's nothing to show. Spectators, with no player, see the scores live
+func (r *Renderer) drawNetwork(player int, started bool, state engine.State, err error, menuKey string) {
+	middle := r.screenHeight() / 2
+	switch {
+	case err != nil:
+		r.drawCenteredText("Disconnected", middle)
+		r.drawCenteredText(err.Error(), middle+16)
+		r.drawCenteredText(fmt.Sprintf("Press '%s' to quit", menuKey), middle+32)
+	case !started && player < 0:
+		r.drawCenteredText("Waiting for the game to start...", middle)
+		r.drawCenteredText(fmt.Sprintf("Press '%s' to quit", menuKey), middle+16)
+	case !started:
+		r.drawCenteredText("Waiting for the other players...", middle)
+		r.drawCenteredText(fmt.Sprintf("Press '%s' to quit", menuKey), middle+16)
+	case state.GameOver || state.GameWon:
+		r.drawCenteredText(outcome(player, state), middle)
+		r.drawCenteredText("The next game starts soon", middle+16)
+	case player < 0:
+		r.drawLive(state)
+	case state.Snakes[player].Dead:
+		r.drawCenteredText("You're out, the others play on", middle)
+		r.drawLive(state)
+	case state.Tick == 0:
+		r.drawCenteredText(fmt.Sprintf("You are player %d", player+1), middle)
+		r.drawCenteredText("Get ready!", middle+16)
+	default:
+		label := fmt.Sprintf("Player %d", player+1)
+		text.Draw(r.screen, label, r.face, 5, 15, r.visuals.Text.ToRGBA())
+	}
+}
+
+// drawLive draws the score and the length of each snake in the top left corner, for spectators and the players
+// who are out
+func (r *Renderer) drawLive(state engine.State) {
+	text.Draw(r.screen, "LIVE", r.face, 5, 15, r.visuals.Text.ToRGBA())
+	for i, snake := range state.Snakes {
+		line := fmt.Sprintf("Score: %d  Length: %d", state.Scores[i], len(snake.Body))
+		if len(state.Snakes) > 1 {
+			line = fmt.Sprintf("P%d: %d  Length: %d", i+1, state.Scores[i], len(snake.Body))
+		}
+		if snake.Dead {
+			line += "  Out"
+		}
+		text.Draw(r.screen, line, r.face, 5, 31+i*16, r.visuals.Text.ToRGBA())
+	}
+}
+
+// outcome describes how a game ended, as seen by a player or a spectator
+func outcome(player int, state engine.State) string {
+	switch {
+	case len(state.Snakes) == 1 && state.GameWon:
+		return fmt.Sprintf("Won with %d points!", state.Scores[0])
+	case len(state.Snakes) == 1:
+		return fmt.Sprintf("Game over with %d points", state.Scores[0])
+	case state.Winner >= 0 && state.Winner == player:
+		return "You won!"
+	case state.Winner >= 0:
+		return fmt.Sprintf("Player %d wins!", state.Winner+1)
+	}
+	return "Draw!"
+}
+
+// drawPaused draws paused game text and resume instructions
+func (r *Renderer) drawPaused(pauseKey, menuKey string) {
+	r.drawCenteredText("You paused the game", r.screenHeight()/2-16)
+	r.drawCenteredText(fmt.Sprintf("Press '%s' to resume", pauseKey), r.screenHeight()/2)
+	r.drawCenteredText(fmt.Sprintf("Press '%s' for the menu", menuKey), r.screenHeight()/2+16)
+}
+
+// drawNameEntry draws the prompt asking the player's name for the high scores
+func (r *Renderer) drawNameEntry(score int, name string) {
+	r.drawCenteredText(fmt.Sprintf("You scored %d", score), r.screenHeight()/2-16)
+	r.drawCenteredText("Enter your name:", r.screenHeight()/2)
+	r.drawCenteredText(name+"_", r.screenHeight()/2+16)
+}
+
+// drawMenu draws a list of items, marking the selected one
+func (r *Renderer) drawMenu(items []string, selected int) {
+	startY := r.screenHeight()/2 - len(items)*16/2
+	for i, item := range items {
+		if i == selected {
+			item = "> " + item + " <"
+		}
+		r.drawCenteredText(item, startY+i*16)
+	}
+}
+
+// drawControls draws the keys bound to each action, marking the selected one
+func (r *Renderer) drawControls(lines []string, selected int, message string) {
+	startY := 20
+	for i, line := range lines {
+		if i == selected {
+			line = "> " + line
+		} else {
+			line = "  " + line
+		}
+		text.Draw(r.screen, line, r.face, 10, startY+i*14, r.visuals.Text.ToRGBA())
+	}
+	r.drawCenteredText(message, r.screenHeight()-20)
+}
+
+// drawEditor draws the spawn of a level and the direction the snake starts in, the cells without food,
+// and the editor's status and help over them
+func (r *Renderer) drawEditor(l *level.Level, message, status string, help []string) {
+	faded := r.visuals.Wall.ToRGBA()
+	faded.A /= 3
+	for _, p := range l.NoFood {
+		r.drawTile(p, faded)
+	}
+	r.drawSnake([]vars.Point{l.Spawn}, 0)
+	ahead := r.visuals.Snake.ToRGBA()
+	ahead.A /= 3
+	r.drawTile(r.board.Neighbor(l.Spawn, l.Direction), ahead)
+
+	r.drawCenteredText(message, 15)
+	r.drawCenteredText(status, 31)
+	startY := r.screenHeight()/2 - len(help)*16/2
+	for i, line := range help {
+		r.drawCenteredText(line, startY+i*16)
+	}
+}
+
+// drawSeed draws the seed of the current game in the top left corner
+func (r *Renderer) drawSeed(seed int64) {
+	seedText := fmt.Sprintf("Seed: %d", seed)
+	text.Draw(r.screen, seedText, r.face, 5, 15, r.visuals.Text.ToRGBA())
+}
@@ -0,0 +1,23 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"GoSnake/replay"
+)
+
+// replayDir is the directory where the replays of finished games are written
+const replayDir = "replays"
+
+// SaveReplay writes the replay of a finished game in the replay directory and returns its path
+func SaveReplay(r *replay.Replay) (string, error) {
+	if err := os.MkdirAll(replayDir, 0755); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%d.gsr", time.Now().Format("20060102-150405"), r.Seed)
+	path := filepath.Join(replayDir, name)
+	return path, replay.Write(path, r)
+}
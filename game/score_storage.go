@@ -3,19 +3,23 @@ package game
 import (
 	"bufio"
 	"fmt"
+	"log"
 	"os"
 	"sort"
 	"strings"
+
+	"GoSnake/event"
 )
 
 // ScoreEntry represents a single entry in the score file
 type ScoreEntry struct {
 	Name  string // The name of the player
 	Score int    // The score achieved by the player
+	Proof string // The path of the replay proving the score, empty if there is none
 }
 
-// SaveScore saves the current score to a file
-func SaveScore(score int) error {
+// SaveScore saves the score of a player to a file, along with the replay proving it
+func SaveScore(name string, score int, proof string) error {
 	// If the score is 0, don't save it
 	if score == 0 {
 		return nil
@@ -27,11 +31,25 @@ func SaveScore(score int) error {
 	}
 	defer file.Close()
 
-	// Example: Save with a placeholder name and score
-	_, err = file.WriteString(fmt.Sprintf("Player: %d\n", score))
+	line := fmt.Sprintf("%s: %d", name, score)
+	if proof != "" {
+		line += " " + proof
+	}
+	_, err = file.WriteString(line + "\n")
 	return err
 }
 
+// RecordScore saves the score entered by the player, it's meant to be subscribed to the event bus
+func RecordScore(e event.Event) {
+	entered, ok := e.(event.ScoreEntered)
+	if !ok {
+		return
+	}
+	if err := SaveScore(entered.Name, entered.Score, entered.Proof); err != nil {
+		log.Printf("Error saving score: %v", err)
+	}
+}
+
 // LoadScores loads scores from a file and returns a sorted slice of ScoreEntry
 func LoadScores() ([]ScoreEntry, error) {
 	// Open the score file
@@ -49,11 +67,12 @@ func LoadScores() ([]ScoreEntry, error) {
 		// Split the line into name and score
 		parts := strings.Split(line, ": ")
 		if len(parts) == 2 {
-			// Parse the score as an integer
+			// Parse the score as an integer, followed by the proof if there is one
 			var score int
-			fmt.Sscanf(parts[1], "%d", &score)
+			var proof string
+			fmt.Sscanf(parts[1], "%d %s", &score, &proof)
 			// Add the score entry to the list
-			scores = append(scores, ScoreEntry{Name: parts[0], Score: score})
+			scores = append(scores, ScoreEntry{Name: parts[0], Score: score, Proof: proof})
 		}
 	}
 
@@ -0,0 +1,135 @@
+package game
+
+import (
+	"fmt"
+)
+
+// ScreenID identifies a state of the game's state machine
+type ScreenID int
+
+const (
+	TitleScreen     ScreenID = iota // Waiting for the player to start
+	CountdownScreen                 // Counting down before the snake starts moving
+	PlayingScreen                   // The snake is moving
+	PausedScreen                    // The game is paused
+	GameOverScreen                  // The snake died
+	WonScreen                       // The player reached the winning score
+	NameEntryScreen                 // The player types their name for the high scores
+	MenuScreen                      // The in-game menu
+	ControlsScreen                  // The player rebinds the keys
+	EditorScreen                    // The player draws a level
+	NetworkScreen                   // The player plays on a server
+)
+
+// String returns a readable name for the screen
+func (id ScreenID) String() string {
+	switch id {
+	case TitleScreen:
+		return "Title"
+	case CountdownScreen:
+		return "Countdown"
+	case PlayingScreen:
+		return "Playing"
+	case PausedScreen:
+		return "Paused"
+	case GameOverScreen:
+		return "GameOver"
+	case WonScreen:
+		return "Won"
+	case NameEntryScreen:
+		return "NameEntry"
+	case MenuScreen:
+		return "Menu"
+	case ControlsScreen:
+		return "Controls"
+	case EditorScreen:
+		return "Editor"
+	case NetworkScreen:
+		return "Network"
+	}
+	return fmt.Sprintf("ScreenID(%d)", int(id))
+}
+
+// Screen is a state of the game with its own update and drawing
+type Screen interface {
+	Enter(g *Game, from ScreenID) // Enter is called when the state machine switches to the screen from another one
+	Exit(g *Game)                 // Exit is called when the state machine leaves the screen
+	Update(g *Game) ScreenID      // Update handles a frame and returns the screen to show next
+	Draw(g *Game)                 // Draw draws the screen over the board
+}
+
+// transitions lists the screens each screen is allowed to switch to
+var transitions = map[ScreenID][]ScreenID{
+	TitleScreen:     {CountdownScreen, ControlsScreen, EditorScreen},
+	CountdownScreen: {PlayingScreen},
+	PlayingScreen:   {PausedScreen, CountdownScreen, NameEntryScreen, GameOverScreen, WonScreen},
+	PausedScreen:    {PlayingScreen, CountdownScreen, MenuScreen},
+	MenuScreen:      {PausedScreen, PlayingScreen, CountdownScreen, TitleScreen, ControlsScreen, EditorScreen},
+	ControlsScreen:  {TitleScreen, MenuScreen},
+	EditorScreen:    {TitleScreen, CountdownScreen},
+	NameEntryScreen: {GameOverScreen, WonScreen},
+	GameOverScreen:  {CountdownScreen},
+	WonScreen:       {CountdownScreen},
+	NetworkScreen:   {},
+}
+
+// StateMachine switches between the screens of the game
+type StateMachine struct {
+	screens map[ScreenID]Screen // The screen implementing each state
+	current ScreenID            // The screen currently shown
+}
+
+// NewStateMachine creates a state machine showing the initial screen once started
+func NewStateMachine(screens map[ScreenID]Screen, initial ScreenID) *StateMachine {
+	return &StateMachine{screens: screens, current: initial}
+}
+
+// Start enters the initial screen
+func (sm *StateMachine) Start(g *Game) {
+	sm.screens[sm.current].Enter(g, sm.current)
+}
+
+// Current returns the screen currently shown
+func (sm *StateMachine) Current() ScreenID {
+	return sm.current
+}
+
+// Update updates the current screen and switches to the screen it asks for
+func (sm *StateMachine) Update(g *Game) error {
+	next := sm.screens[sm.current].Update(g)
+	if next == sm.current {
+		return nil
+	}
+	return sm.Switch(g, next)
+}
+
+// Draw draws the current screen
+func (sm *StateMachine) Draw(g *Game) {
+	sm.screens[sm.current].Draw(g)
+}
+
+// Switch leaves the current screen and enters the next one, it fails if the transition isn't allowed
+func (sm *StateMachine) Switch(g *Game, next ScreenID) error {
+	if !sm.allowed(next) {
+		return fmt.Errorf("invalid screen transition from %v to %v", sm.current, next)
+	}
+	screen, ok := sm.screens[next]
+	if !ok {
+		return fmt.Errorf("no screen registered for %v", next)
+	}
+	sm.screens[sm.current].Exit(g)
+	previous := sm.current
+	sm.current = next
+	screen.Enter(g, previous)
+	return nil
+}
+
+// allowed reports whether the current screen may switch to the next one
+func (sm *StateMachine) allowed(next ScreenID) bool {
+	for _, id := range transitions[sm.current] {
+		if id == next {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"GoSnake/config"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+// controlsScreen lets the player rebind the keys of every action, the changes are saved to the
+// configuration file when leaving. It's driven by the arrow keys, 'ENTER' and 'ESCAPE' so it stays
+// usable whatever the bindings are
+type controlsScreen struct {
+	back      ScreenID // The screen to go back to
+	selected  int      // The index of the highlighted line: an action, then reset, then back
+	capturing bool     // Whether the next key pressed is bound to the selected action
+	changed   bool     // Whether the bindings changed since entering the screen
+	message   string   // The hint or feedback shown at the bottom
+}
+
+// Enter remembers where to go back to
+func (s *controlsScreen) Enter(g *Game, from ScreenID) {
+	s.back = from
+	s.selected = 0
+	s.capturing = false
+	s.changed = false
+	s.message = "ENTER: add a key, DELETE: remove one, ESCAPE: back"
+}
+
+// Exit saves the bindings if they changed
+func (s *controlsScreen) Exit(g *Game) {
+	if !s.changed {
+		return
+	}
+	if err := config.SaveControls(g.settings, g.bindings.Controls()); err != nil {
+		log.Printf("Error saving controls: %v", err)
+	}
+}
+
+// Update moves the selection, or binds the pressed key when capturing
+func (s *controlsScreen) Update(g *Game) ScreenID {
+	if s.capturing {
+		s.capture(g)
+		return ControlsScreen
+	}
+
+	lines := len(Actions) + 2
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		return s.back
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		s.selected = (s.selected + lines - 1) % lines
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		s.selected = (s.selected + 1) % lines
+	case inpututil.IsKeyJustPressed(ebiten.KeyDelete) || inpututil.IsKeyJustPressed(ebiten.KeyBackspace):
+		if s.selected < len(Actions) {
+			s.removeLast(g, Actions[s.selected])
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		switch {
+		case s.selected < len(Actions):
+			s.capturing = true
+			s.message = fmt.Sprintf("Press a key for %s, ESCAPE to cancel", Actions[s.selected])
+		case s.selected == len(Actions):
+			defaults, _ := NewBindings(config.Default().Controls)
+			g.bindings = defaults
+			s.changed = true
+			s.message = "Controls reset to the defaults"
+		default:
+			return s.back
+		}
+	}
+	return ControlsScreen
+}
+
+// capture binds the key pressed during this frame to the selected action
+func (s *controlsScreen) capture(g *Game) {
+	key, ok := justPressedKey()
+	if !ok {
+		return
+	}
+	s.capturing = false
+	if key == ebiten.KeyEscape {
+		s.message = "Cancelled"
+		return
+	}
+
+	// A key triggers a single action, take it from the others unless it's the only key they have
+	action := Actions[s.selected]
+	for _, other := range g.bindings.ActionsOf(key) {
+		if other == action {
+			s.message = fmt.Sprintf("%s is already bound to %s", key, action)
+			return
+		}
+		if len(g.bindings[other]) == 1 {
+			s.message = fmt.Sprintf("%s is the only key for %s", key, other)
+			return
+		}
+	}
+	for _, other := range g.bindings.ActionsOf(key) {
+		g.bindings.Unbind(other, key)
+	}
+	g.bindings.Bind(action, key)
+	s.changed = true
+	s.message = fmt.Sprintf("%s bound to %s", key, action)
+}
+
+// removeLast removes the last key of an action, an action always keeps at least one key
+func (s *controlsScreen) removeLast(g *Game, action Action) {
+	keys := g.bindings[action]
+	if len(keys) <= 1 {
+		s.message = fmt.Sprintf("%s needs at least one key", action)
+		return
+	}
+	g.bindings.Unbind(action, keys[len(keys)-1])
+	s.changed = true
+	s.message = fmt.Sprintf("%s unbound from %s", keys[len(keys)-1], action)
+}
+
+// Draw draws every action with its keys
+func (s *controlsScreen) Draw(g *Game) {
+	var lines []string
+	for _, action := range Actions {
+		var names []string
+		for _, key := range g.bindings[action] {
+			names = append(names, key.String())
+		}
+		lines = append(lines, fmt.Sprintf("%-8s %s", action, strings.Join(names, ", ")))
+	}
+	lines = append(lines, "Reset to defaults", "Back")
+	g.renderer.drawControls(lines, s.selected, s.message)
+}
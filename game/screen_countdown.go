@@ -0,0 +1,34 @@
+package game
+
+import (
+	"time"
+)
+
+// countdownDuration is how long the player has to get ready before the snake moves
+const countdownDuration = 3 * time.Second
+
+// countdownScreen gives the player a few seconds before the snake starts moving
+type countdownScreen struct {
+	start time.Time // When the countdown started
+}
+
+// Enter starts the countdown
+func (s *countdownScreen) Enter(g *Game, from ScreenID) {
+	s.start = time.Now()
+}
+
+func (s *countdownScreen) Exit(g *Game) {}
+
+// Update starts the game once the countdown is over
+func (s *countdownScreen) Update(g *Game) ScreenID {
+	if time.Since(s.start) >= countdownDuration {
+		return PlayingScreen
+	}
+	return CountdownScreen
+}
+
+// Draw draws the number of seconds left, rounded up
+func (s *countdownScreen) Draw(g *Game) {
+	left := countdownDuration - time.Since(s.start)
+	g.renderer.drawCountdown(int((left + time.Second - 1) / time.Second))
+}
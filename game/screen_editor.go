@@ -0,0 +1,175 @@
+package game
+
+import (
+	"fmt"
+	"log"
+
+	"GoSnake/engine"
+	"GoSnake/level"
+	"GoSnake/vars"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+// editorPath is where the level editor saves and loads its level
+const editorPath = "levels/custom.json"
+
+// editorHelp lists the editor's controls, it's driven by the mouse and fixed keys so it works whatever the bindings are
+var editorHelp = []string{
+	"Left click: paint, right click: erase",
+	"1: walls, 2: no food",
+	"S: spawn, arrows: direction",
+	"W: wrap, C: clear",
+	"ENTER: play, ESCAPE: title",
+	"CTRL+S: save, CTRL+O: load",
+}
+
+// editorScreen lets the player paint the walls of a level with the mouse, then play it or save it.
+// The left button paints with the selected tool and the right button erases
+type editorScreen struct {
+	level   *level.Level // The level being edited
+	tool    byte         // What the left button paints: level.Wall or level.NoFood
+	message string       // The feedback shown at the top
+}
+
+// Enter edits the level being played, or an empty board the size of the current one
+func (s *editorScreen) Enter(g *Game, from ScreenID) {
+	if g.rules.Level != nil {
+		s.level = g.rules.Level.Clone()
+	} else {
+		s.level = level.New("Custom", g.rules.Board.Width, g.rules.Board.Height)
+	}
+	s.tool = level.Wall
+	s.message = fmt.Sprintf("Editing %s", editorPath)
+	s.show(g)
+}
+
+func (s *editorScreen) Exit(g *Game) {}
+
+// Update applies the mouse and keys to the level
+func (s *editorScreen) Update(g *Game) ScreenID {
+	control := ebiten.IsKeyPressed(ebiten.KeyControl)
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		g.restart()
+		return TitleScreen
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		// Play the level from now on, until another one is edited
+		rules := s.rules(g, s.level.Clone())
+		if err := rules.Validate(); err != nil {
+			s.message = err.Error()
+			break
+		}
+		g.rules = rules
+		g.restart()
+		return CountdownScreen
+	case control && inpututil.IsKeyJustPressed(ebiten.KeyS):
+		s.save()
+	case control && inpututil.IsKeyJustPressed(ebiten.KeyO):
+		s.load(g)
+	case inpututil.IsKeyJustPressed(ebiten.Key1):
+		s.tool = level.Wall
+	case inpututil.IsKeyJustPressed(ebiten.Key2):
+		s.tool = level.NoFood
+	case inpututil.IsKeyJustPressed(ebiten.KeyS):
+		s.level.Set(s.cursor(g), level.Spawn)
+	case inpututil.IsKeyJustPressed(ebiten.KeyW):
+		s.level.Wrap = !s.level.Wrap
+		s.show(g)
+	case inpututil.IsKeyJustPressed(ebiten.KeyC):
+		s.level = level.New(s.level.Name, s.level.Width, s.level.Height)
+		s.show(g)
+	}
+
+	// The direction keys are fixed like the others, the bindings may have moved them
+	for key, direction := range map[ebiten.Key]vars.Point{
+		ebiten.KeyUp:    {X: 0, Y: -1},
+		ebiten.KeyDown:  {X: 0, Y: 1},
+		ebiten.KeyLeft:  {X: -1, Y: 0},
+		ebiten.KeyRight: {X: 1, Y: 0},
+	} {
+		if inpututil.IsKeyJustPressed(key) {
+			s.level.Direction = direction
+		}
+	}
+
+	// Paint while the buttons are held so walls can be drawn in one stroke
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && s.cursor(g) != s.level.Spawn {
+		s.level.Set(s.cursor(g), s.tool)
+	} else if ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) && s.cursor(g) != s.level.Spawn {
+		s.level.Set(s.cursor(g), level.Empty)
+	}
+	g.state.Portals = s.level.Portals // Painting over an end of a portal removes it
+	return EditorScreen
+}
+
+// Draw draws the spawn and the cells without food over the board, the help is shown while 'H' is held
+func (s *editorScreen) Draw(g *Game) {
+	tool := "walls"
+	if s.tool == level.NoFood {
+		tool = "no food"
+	}
+	wrap := "off"
+	if s.level.Wrap {
+		wrap = "on"
+	}
+	status := fmt.Sprintf("Tool: %s, wrap: %s, H: help", tool, wrap)
+
+	var help []string
+	if ebiten.IsKeyPressed(ebiten.KeyH) {
+		help = editorHelp
+	}
+	g.renderer.drawEditor(s.level, s.message, status, help)
+}
+
+// cursor returns the cell under the mouse
+func (s *editorScreen) cursor(g *Game) vars.Point {
+	x, y := ebiten.CursorPosition()
+	if x < 0 || y < 0 {
+		return vars.Point{X: -1, Y: -1} // Outside the window, Set ignores it
+	}
+	return g.renderer.cellAt(x, y)
+}
+
+// rules returns the rules of the session played in a level, which decides alone whether the board wraps
+func (s *editorScreen) rules(g *Game, l *level.Level) engine.Rules {
+	rules := g.rules.WithLevel(l)
+	rules.Board.Wrap = l.Wrap
+	return rules
+}
+
+// show makes the game draw the board of the level being edited
+func (s *editorScreen) show(g *Game) {
+	g.state.Rules = s.rules(g, s.level)
+	g.state.Portals = s.level.Portals
+	g.state.Snakes = nil
+	g.state.Foods = nil
+}
+
+// save writes the level once it's playable
+func (s *editorScreen) save() {
+	if err := s.level.Validate(); err != nil {
+		s.message = err.Error()
+		return
+	}
+	if err := level.Save(editorPath, s.level); err != nil {
+		log.Printf("Error saving level: %v", err)
+		s.message = "Error saving the level"
+		return
+	}
+	s.message = fmt.Sprintf("Saved to %s", editorPath)
+}
+
+// load replaces the level with the one saved last
+func (s *editorScreen) load(g *Game) {
+	l, err := level.Load(editorPath)
+	if err != nil {
+		log.Printf("Error loading level: %v", err)
+		s.message = "Error loading the level"
+		return
+	}
+	s.level = l
+	s.message = fmt.Sprintf("Loaded %s", editorPath)
+	s.show(g)
+}
@@ -0,0 +1,30 @@
+package game
+
+// endScreen shows the result of a finished game until the player restarts
+type endScreen struct {
+	id ScreenID // Either GameOverScreen or WonScreen
+}
+
+func (s *endScreen) Enter(g *Game, from ScreenID) {}
+
+func (s *endScreen) Exit(g *Game) {}
+
+// Update restarts the game when the restart key is pressed
+func (s *endScreen) Update(g *Game) ScreenID {
+	if g.justPressed(ActionRestart) {
+		g.restart()
+		return CountdownScreen
+	}
+	return s.id
+}
+
+// Draw shows the result, a game between several players names the winner instead of showing the high scores
+func (s *endScreen) Draw(g *Game) {
+	if len(g.state.Snakes) > 1 {
+		g.renderer.drawResult(g.state.Winner, g.state.Scores, g.state.Seed, g.bindings.Name(ActionRestart))
+	} else if s.id == WonScreen {
+		g.renderer.drawWon(g.state.Seed, g.bindings.Name(ActionRestart))
+	} else {
+		g.renderer.drawGameOver(g.state.Seed, g.bindings.Name(ActionRestart))
+	}
+}
@@ -0,0 +1,79 @@
+package game
+
+import (
+	"GoSnake/event"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+// menuItem is an entry of the in-game menu
+type menuItem struct {
+	label  string                 // The text shown for the entry
+	action func(g *Game) ScreenID // What happens when the entry is chosen, returns the next screen
+}
+
+// menuItems are the entries of the in-game menu
+var menuItems = []menuItem{
+	{label: "Resume", action: func(g *Game) ScreenID {
+		g.bus.Publish(event.Resumed{})
+		return PlayingScreen
+	}},
+	{label: "Restart", action: func(g *Game) ScreenID {
+		g.restart()
+		return CountdownScreen
+	}},
+	{label: "Quit to title", action: func(g *Game) ScreenID {
+		g.restart()
+		return TitleScreen
+	}},
+	{label: "Controls", action: func(g *Game) ScreenID {
+		return ControlsScreen
+	}},
+	{label: "Edit level", action: func(g *Game) ScreenID {
+		if g.player != nil {
+			return MenuScreen // The replay decides the level
+		}
+		return EditorScreen
+	}},
+	{label: "Save & quit", action: func(g *Game) ScreenID {
+		g.quit = true // The game manager saves the game when it's closed
+		return MenuScreen
+	}},
+}
+
+// menuScreen lets the player pick an action while the game is paused
+type menuScreen struct {
+	selected int // The index of the highlighted item
+}
+
+// Enter highlights the first item
+func (s *menuScreen) Enter(g *Game, from ScreenID) {
+	s.selected = 0
+}
+
+func (s *menuScreen) Exit(g *Game) {}
+
+// Update moves the selection and runs the selected item
+func (s *menuScreen) Update(g *Game) ScreenID {
+	switch {
+	case g.justPressed(ActionMenu):
+		return PausedScreen
+	case g.justPressed(ActionUp):
+		s.selected = (s.selected + len(menuItems) - 1) % len(menuItems)
+	case g.justPressed(ActionDown):
+		s.selected = (s.selected + 1) % len(menuItems)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) || g.justPressed(ActionStart):
+		return menuItems[s.selected].action(g)
+	}
+	return MenuScreen
+}
+
+// Draw draws the labels of the items
+func (s *menuScreen) Draw(g *Game) {
+	labels := make([]string, len(menuItems))
+	for i, item := range menuItems {
+		labels[i] = item.label
+	}
+	g.renderer.drawMenu(labels, s.selected)
+}
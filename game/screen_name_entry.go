@@ -0,0 +1,56 @@
+package game
+
+import (
+	"unicode"
+
+	"GoSnake/event"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+const (
+	maxNameLength = 12       // The longest name that fits in the high scores
+	defaultName   = "Player" // The name used when the player doesn't type one
+)
+
+// nameEntryScreen asks the player's name to keep their score
+type nameEntryScreen struct {
+	name []rune // The name typed so far
+}
+
+// Enter clears the name typed for the previous game
+func (s *nameEntryScreen) Enter(g *Game, from ScreenID) {
+	s.name = s.name[:0]
+}
+
+func (s *nameEntryScreen) Exit(g *Game) {}
+
+// Update edits the name and submits the score when 'ENTER' is pressed
+func (s *nameEntryScreen) Update(g *Game) ScreenID {
+	for _, c := range ebiten.InputChars() {
+		if len(s.name) < maxNameLength && (unicode.IsLetter(c) || unicode.IsDigit(c) || c == '-' || c == '_') {
+			s.name = append(s.name, c)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(s.name) > 0 {
+		s.name = s.name[:len(s.name)-1]
+	}
+	if !inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		return NameEntryScreen
+	}
+
+	name := string(s.name)
+	if name == "" {
+		name = defaultName
+	}
+	g.bus.Publish(event.ScoreEntered{Name: name, Score: g.state.Scores[0], Proof: g.proof})
+	if g.state.GameWon {
+		return WonScreen
+	}
+	return GameOverScreen
+}
+
+func (s *nameEntryScreen) Draw(g *Game) {
+	g.renderer.drawNameEntry(g.state.Scores[0], string(s.name))
+}
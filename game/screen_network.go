@@ -0,0 +1,54 @@
+package game
+
+import (
+	"log"
+
+	"GoSnake/control"
+)
+
+// networkScreen plays on a server: it sends the player's turns and shows the game the server sends back.
+// The server decides when games start and end, so there's no pausing nor restarting. Spectators only
+// see the game, whether it's played on a server or broadcast from another window
+type networkScreen struct{}
+
+// Enter steers the snake the server gives the player, from the keyboard or the gamepads, or with a bot
+func (s *networkScreen) Enter(g *Game, from ScreenID) {
+	g.controllers = g.newControllers(1)
+}
+
+func (s *networkScreen) Exit(g *Game) {}
+
+// Update sends the turns of the player's controller and takes the last game received
+func (s *networkScreen) Update(g *Game) ScreenID {
+	if g.justPressed(ActionMenu) {
+		g.quit = true
+		return NetworkScreen
+	}
+	if g.client.Err() != nil {
+		return NetworkScreen
+	}
+
+	// What's pressed before a game starts, between two games or by a spectator is dropped rather than sent
+	// all at once when the next game starts
+	state, ok := g.client.State()
+	if !ok {
+		return NetworkScreen
+	}
+	g.state = state
+
+	if player := g.client.Player(); player >= 0 && !state.GameOver && !state.GameWon {
+		g.poll()
+		for _, direction := range g.controllers[0].Turns(control.NewView(state, player)) {
+			if err := g.client.Turn(direction); err != nil {
+				log.Printf("Error sending a turn: %v", err)
+			}
+		}
+	}
+	return NetworkScreen
+}
+
+// Draw tells the player which snake is theirs and what the server is doing
+func (s *networkScreen) Draw(g *Game) {
+	_, started := g.client.State()
+	g.renderer.drawNetwork(g.client.Player(), started, g.state, g.client.Err(), g.bindings.Name(ActionMenu))
+}
@@ -0,0 +1,32 @@
+package game
+
+import (
+	"GoSnake/event"
+)
+
+// pausedScreen freezes the game until the player resumes it
+type pausedScreen struct{}
+
+func (s *pausedScreen) Enter(g *Game, from ScreenID) {}
+
+func (s *pausedScreen) Exit(g *Game) {}
+
+// Update resumes, restarts or opens the menu
+func (s *pausedScreen) Update(g *Game) ScreenID {
+	if g.justPressed(ActionPause) {
+		g.bus.Publish(event.Resumed{})
+		return PlayingScreen
+	}
+	if g.justPressed(ActionRestart) {
+		g.restart()
+		return CountdownScreen
+	}
+	if g.justPressed(ActionMenu) {
+		return MenuScreen
+	}
+	return PausedScreen
+}
+
+func (s *pausedScreen) Draw(g *Game) {
+	g.renderer.drawPaused(g.bindings.Name(ActionPause), g.bindings.Name(ActionMenu))
+}
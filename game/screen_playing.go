@@ -0,0 +1,53 @@
+package game
+
+import (
+	"GoSnake/event"
+)
+
+// playingScreen moves the snake according to the player's input
+type playingScreen struct{}
+
+func (s *playingScreen) Enter(g *Game, from ScreenID) {}
+
+// Exit stops the clock so the snake doesn't jump forward when the game comes back to this screen
+func (s *playingScreen) Exit(g *Game) {
+	g.logic.Suspend()
+}
+
+// Update handles the player's input and moves the snake through the engine
+func (s *playingScreen) Update(g *Game) ScreenID {
+	// If the restart key is pressed, restart the game
+	if g.justPressed(ActionRestart) {
+		g.restart()
+		return CountdownScreen
+	}
+
+	// If the pause key is pressed, pause the game
+	if g.justPressed(ActionPause) {
+		g.bus.Publish(event.Paused{})
+		return PausedScreen
+	}
+
+	// Remember the directions requested by the players until the next move
+	g.poll()
+
+	// Move the snake through the engine as many times as the elapsed time allows
+	g.logic.UpdateTick()
+	for g.logic.NextMove(g.state.MoveInterval) {
+		g.step()
+	}
+
+	// Ask for the player's name if a game played alone, not by a bot, ended with a score worth keeping
+	if g.state.GameOver || g.state.GameWon {
+		if len(g.state.Scores) == 1 && g.state.Scores[0] > 0 && g.player == nil && g.human(0) {
+			return NameEntryScreen
+		}
+		if g.state.GameWon {
+			return WonScreen
+		}
+		return GameOverScreen
+	}
+	return PlayingScreen
+}
+
+func (s *playingScreen) Draw(g *Game) {}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"errors"
+	"log"
+
+	"GoSnake/engine"
+	"GoSnake/replay"
+	"GoSnake/save"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/inpututil"
+)
+
+// titleScreen waits for the player to start a new game or continue the saved one
+type titleScreen struct {
+	saved     engine.State   // The game saved by the previous session
+	recording *replay.Replay // The recording of the saved game
+	hasSaved  bool           // Whether there is a saved game to continue
+}
+
+// Enter looks for a saved game to offer, unless a replay is being watched
+func (s *titleScreen) Enter(g *Game, from ScreenID) {
+	if g.player != nil {
+		s.hasSaved = false
+		return
+	}
+	var err error
+	s.saved, s.recording, err = save.Read(save.Path)
+	s.hasSaved = err == nil
+	if err != nil && !errors.Is(err, save.ErrNoSave) {
+		log.Printf("Error loading saved game: %v", err)
+	}
+}
+
+func (s *titleScreen) Exit(g *Game) {}
+
+// Update starts the countdown when the start key is pressed, continues the saved game when 'C' is pressed
+// opens the controls when the menu key is pressed or the level editor when 'E' is pressed
+func (s *titleScreen) Update(g *Game) ScreenID {
+	if g.justPressed(ActionStart) {
+		return CountdownScreen
+	}
+	if g.justPressed(ActionMenu) {
+		return ControlsScreen
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) && g.player == nil {
+		return EditorScreen
+	}
+	if s.hasSaved && inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		// The save is consumed so the same run can't be continued twice
+		if err := save.Remove(save.Path); err != nil {
+			log.Printf("Error removing saved game: %v", err)
+		}
+		g.resume(s.saved, s.recording)
+		s.hasSaved = false
+		return CountdownScreen
+	}
+	return TitleScreen
+}
+
+func (s *titleScreen) Draw(g *Game) {
+	g.renderer.drawTitle(g.bindings.Name(ActionStart), g.bindings.Name(ActionMenu), s.hasSaved)
+}
@@ -0,0 +1,211 @@
+// Package gym exposes the game as an environment to train agents on, in the manner of Gym: the agent resets
+// the environment, then steps it with an action at a time and gets back what it sees, a reward and whether
+// the game is over. It runs the engine without a window
+package gym
+
+import (
+	"errors"
+	"fmt"
+
+	"GoSnake/control"
+	"GoSnake/engine"
+	"GoSnake/event"
+	"GoSnake/vars"
+)
+
+// The values of the cells of an observation
+const (
+	CellEmpty   = iota // Nothing
+	CellWall           // A wall of the level
+	CellBody           // A segment of the snake other than its head
+	CellHead           // The head of the snake
+	CellFood           // A food adding points, or none
+	CellBonus          // The bonus food
+	CellPortal         // An end of a portal
+	CellPoison         // A food killing the snake
+	CellPenalty        // A food taking points away
+)
+
+// DeathReward is the reward given when the snake dies
+const DeathReward = -1
+
+// ErrNotReset is returned by Step before the first Reset and once the game is over
+var ErrNotReset = errors.New("the environment must be reset first")
+
+// Actions lists the direction of each action: up, down, left and right. Going back or the way the snake is
+// already going does nothing. It's a copy, so changing it doesn't change the directions the bots try
+var Actions = append([]vars.Point(nil), control.Directions...)
+
+// Options change the game played after a reset, the zero value keeps the rules of the environment
+type Options struct {
+	Seed   *int64 `json:"seed,omitempty"`   // The seed of the game, the one after the previous game's when nil
+	Width  int    `json:"width,omitempty"`  // The number of cells in a row, 0 to keep the width of the rules
+	Height int    `json:"height,omitempty"` // The number of cells in a column, 0 to keep the height of the rules
+	Wrap   *bool  `json:"wrap,omitempty"`   // Whether the snake goes through the edges, nil to keep the rules
+}
+
+// Result is what the agent gets back from a reset or a step
+type Result struct {
+	Observation [][]int `json:"observation"` // The board, row by row, with a Cell value for each cell
+	Reward      float64 `json:"reward"`      // The points scored by the step, or DeathReward if the snake died
+	Done        bool    `json:"done"`        // Whether the game is over, the snake died or won
+	Truncated   bool    `json:"truncated"`   // Whether the game was stopped because the snake stopped eating
+	Info        Info    `json:"info"`        // More about the game
+}
+
+// Info tells more about the game than the observation
+type Info struct {
+	Seed      int64      `json:"seed"`            // The seed of the game
+	Tick      int        `json:"tick"`            // The number of moves made
+	Score     int        `json:"score"`           // The score of the snake
+	Length    int        `json:"length"`          // The length of the snake
+	Head      vars.Point `json:"head"`            // The cell of the head
+	Direction vars.Point `json:"direction"`       // The direction the snake is going
+	Won       bool       `json:"won"`             // Whether the winning score was reached
+	Cause     string     `json:"cause,omitempty"` // What killed the snake, when it died
+}
+
+// Env is an environment playing games of a single snake
+type Env struct {
+	rules engine.Rules // The rules the options are applied to
+	seed  int64        // The seed of the next game when none is given
+	state engine.State // The game being played
+	ready bool         // Whether a game can be stepped
+	last  int          // The tick the snake last ate at
+	cause string       // What killed the snake
+}
+
+// NewEnv returns an environment playing with the given rules, the snake plays alone whatever the rules say.
+// Games get seeds in order from the given one unless a reset asks for another
+func NewEnv(rules engine.Rules, seed int64) *Env {
+	rules.Players = 0
+	return &Env{rules: rules, seed: seed}
+}
+
+// Reset starts a new game and returns its first observation
+func (e *Env) Reset(options Options) (Result, error) {
+	rules := e.rules
+	if options.Width > 0 {
+		rules.Board.Width = options.Width
+	}
+	if options.Height > 0 {
+		rules.Board.Height = options.Height
+	}
+	if options.Wrap != nil {
+		rules.Board.Wrap = *options.Wrap
+	}
+	if err := rules.Validate(); err != nil {
+		return Result{}, err
+	}
+	seed := e.seed
+	if options.Seed != nil {
+		seed = *options.Seed
+	}
+
+	e.state = engine.NewState(rules, seed)
+	e.seed = seed + 1
+	e.ready = true
+	e.last = 0
+	e.cause = ""
+	return e.result(0), nil
+}
+
+// Step moves the snake once after turning it as the action says, and returns what happened
+func (e *Env) Step(action int) (Result, error) {
+	if !e.ready {
+		return Result{}, ErrNotReset
+	}
+	if action < 0 || action >= len(Actions) {
+		return Result{}, fmt.Errorf("invalid action %d, expected 0 to %d", action, len(Actions)-1)
+	}
+
+	var input engine.Input
+	input.Add(0, Actions[action])
+	score := e.state.Scores[0]
+	var events []event.Event
+	e.state, events = engine.Step(e.state, input)
+
+	reward := float64(e.state.Scores[0] - score)
+	for _, ev := range events {
+		switch ev := ev.(type) {
+		case event.SnakeDied:
+			e.cause = ev.Cause.String()
+			reward = DeathReward
+		case event.FoodEaten, event.BonusEaten:
+			e.last = e.state.Tick
+		}
+	}
+	result := e.result(reward)
+	result.Truncated = !result.Done && e.state.Tick-e.last >= e.stallLimit()
+	e.ready = !result.Done && !result.Truncated
+	return result, nil
+}
+
+// stallLimit is the number of moves without eating after which a game is stopped, twice the cells of the board
+// is enough for any snake going somewhere to reach the food
+func (e *Env) stallLimit() int {
+	return 2 * e.state.Rules.Board.Width * e.state.Rules.Board.Height
+}
+
+// result returns what the agent gets back after a move
+func (e *Env) result(reward float64) Result {
+	snake := e.state.Snakes[0]
+	return Result{
+		Observation: e.observe(),
+		Reward:      reward,
+		Done:        e.state.GameOver || e.state.GameWon,
+		Info: Info{
+			Seed:      e.state.Seed,
+			Tick:      e.state.Tick,
+			Score:     e.state.Scores[0],
+			Length:    len(snake.Body),
+			Head:      snake.Body[0],
+			Direction: snake.Direction,
+			Won:       e.state.GameWon,
+			Cause:     e.cause,
+		},
+	}
+}
+
+// observe returns the board as a grid of Cell values, the snake drawn last so it shows over the rest
+func (e *Env) observe() [][]int {
+	board := e.state.Rules.Board
+	grid := make([][]int, board.Height)
+	for y := range grid {
+		grid[y] = make([]int, board.Width)
+	}
+	set := func(p vars.Point, cell int) {
+		if board.Contains(p) {
+			grid[p.Y][p.X] = cell
+		}
+	}
+
+	if e.state.Rules.Level != nil {
+		for _, p := range e.state.Rules.Level.Walls {
+			set(p, CellWall)
+		}
+	}
+	for _, portal := range e.state.Portals {
+		set(portal.A, CellPortal)
+		set(portal.B, CellPortal)
+	}
+	for _, f := range e.state.Foods {
+		switch t := e.state.Rules.FoodType(f.Kind); {
+		case t.Deadly:
+			set(f.Position, CellPoison)
+		case t.Points < 0:
+			set(f.Position, CellPenalty)
+		default:
+			set(f.Position, CellFood)
+		}
+	}
+	if e.state.Bonus.Active {
+		set(e.state.Bonus.Position, CellBonus)
+	}
+	body := e.state.Snakes[0].Body
+	for _, p := range body[1:] {
+		set(p, CellBody)
+	}
+	set(body[0], CellHead)
+	return grid
+}
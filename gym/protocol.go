@@ -0,0 +1,138 @@
+package gym
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"GoSnake/engine"
+)
+
+// The commands of the protocol
+const (
+	CmdReset = "reset" // Starts a new game, with Options
+	CmdStep  = "step"  // Moves the snake, with an action
+	CmdClose = "close" // Ends the session
+)
+
+// Request is a line sent by the agent
+type Request struct {
+	Cmd     string `json:"cmd"`    // What to do, one of the Cmd constants
+	Action  *int   `json:"action"` // The action of a step, an index into Actions, a step without one fails
+	Options        // The options of a reset
+}
+
+// response is a line sent back to the agent, a result or an error
+type response struct {
+	*Result
+	Error string `json:"error,omitempty"` // Why the request failed, the environment is left as it was
+}
+
+// Serve runs a session of the protocol: it reads a request per line of JSON and writes a response per line,
+// until the agent closes the session or the reader ends. A failed request is answered with an error and the
+// session goes on, only failing to read or write ends it early
+func Serve(r io.Reader, w io.Writer, env *Env) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			if err := reply(encoder, out, response{Error: fmt.Sprintf("invalid request: %v", err)}); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Cmd == CmdClose {
+			return nil
+		}
+
+		var result Result
+		var err error
+		switch req.Cmd {
+		case CmdReset:
+			result, err = env.Reset(req.Options)
+		case CmdStep:
+			if req.Action == nil {
+				err = fmt.Errorf("a step needs an action, 0 to %d", len(Actions)-1)
+				break
+			}
+			result, err = env.Step(*req.Action)
+		default:
+			err = fmt.Errorf("unknown command %q, expected %s, %s or %s", req.Cmd, CmdReset, CmdStep, CmdClose)
+		}
+		res := response{Result: &result}
+		if err != nil {
+			res = response{Error: err.Error()}
+		}
+		if err := reply(encoder, out, res); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// reply writes a response and sends it straight away, the agent waits for it before sending anything else
+func reply(encoder *json.Encoder, out *bufio.Writer, res response) error {
+	if err := encoder.Encode(res); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// Server runs sessions of the protocol for agents connecting over TCP, each with an environment of its own
+type Server struct {
+	listener net.Listener // Where the agents connect
+	rules    engine.Rules // The rules of the environments
+	seed     int64        // The seed of the first game of each environment
+
+	wg sync.WaitGroup // The sessions running
+}
+
+// Listen starts listening for agents on a TCP address
+func Listen(addr string, rules engine.Rules, seed int64) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{listener: listener, rules: rules, seed: seed}, nil
+}
+
+// Addr returns the address the agents connect to
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve runs a session for each agent connecting until the server is closed, then waits for the sessions
+// to end
+func (s *Server) Serve() error {
+	defer s.wg.Wait()
+	for {
+		conn, err := s.listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			Serve(conn, conn, NewEnv(s.rules, s.seed))
+		}()
+	}
+}
+
+// Close stops taking agents, the running sessions go on until their agents leave
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
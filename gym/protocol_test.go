@@ -0,0 +1,224 @@
+package gym
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"GoSnake/control"
+	"GoSnake/engine"
+	"GoSnake/food"
+	"GoSnake/vars"
+)
+
+// session runs requests through the protocol and returns the responses
+func session(t *testing.T, env *Env, requests ...string) []response {
+	t.Helper()
+	var out strings.Builder
+	if err := Serve(strings.NewReader(strings.Join(requests, "\n")), &out, env); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	var responses []response
+	decoder := json.NewDecoder(strings.NewReader(out.String()))
+	for decoder.More() {
+		var res response
+		if err := decoder.Decode(&res); err != nil {
+			t.Fatalf("decoding %q: %v", out.String(), err)
+		}
+		responses = append(responses, res)
+	}
+	return responses
+}
+
+func TestServeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests []string
+		want     string // A part of the error of the last response
+	}{
+		{"step before reset", []string{`{"cmd":"step","action":0}`}, ErrNotReset.Error()},
+		{"action out of range", []string{`{"cmd":"reset"}`, `{"cmd":"step","action":4}`}, "invalid action 4"},
+		{"negative action", []string{`{"cmd":"reset"}`, `{"cmd":"step","action":-1}`}, "invalid action -1"},
+		{"step without action", []string{`{"cmd":"reset"}`, `{"cmd":"step"}`}, "needs an action"},
+		{"invalid JSON", []string{`{"cmd":`}, "invalid request"},
+		{"unknown command", []string{`{"cmd":"jump"}`}, `unknown command "jump"`},
+		{"board too small", []string{`{"cmd":"reset","width":1}`}, "at least 2x2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := session(t, NewEnv(engine.DefaultRules(), 1), tt.requests...)
+			if len(responses) != len(tt.requests) {
+				t.Fatalf("got %d responses to %d requests", len(responses), len(tt.requests))
+			}
+			last := responses[len(responses)-1]
+			if !strings.Contains(last.Error, tt.want) || last.Result != nil {
+				t.Errorf("got error %q and result %v, want an error containing %q", last.Error, last.Result, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeReset(t *testing.T) {
+	responses := session(t, NewEnv(engine.DefaultRules(), 7),
+		`{"cmd":"reset","seed":3,"width":6,"height":4}`,
+		`{"cmd":"reset","width":6,"height":4}`,
+		`{"cmd":"reset"}`,
+	)
+	seeds := []int64{3, 4, 5}
+	sizes := [][2]int{{6, 4}, {6, 4}, {64, 48}}
+	for i, res := range responses {
+		if res.Error != "" {
+			t.Fatalf("reset %d: %s", i, res.Error)
+		}
+		if res.Info.Seed != seeds[i] {
+			t.Errorf("reset %d: seed %d, want %d", i, res.Info.Seed, seeds[i])
+		}
+		if res.Done || res.Truncated || res.Reward != 0 || res.Info.Tick != 0 {
+			t.Errorf("reset %d: got done %v, truncated %v, reward %v and tick %d for a new game", i, res.Done, res.Truncated, res.Reward, res.Info.Tick)
+		}
+		if len(res.Observation) != sizes[i][1] || len(res.Observation[0]) != sizes[i][0] {
+			t.Fatalf("reset %d: observation of %dx%d, want %dx%d", i, len(res.Observation[0]), len(res.Observation), sizes[i][0], sizes[i][1])
+		}
+		cells := map[int]int{}
+		for _, row := range res.Observation {
+			for _, cell := range row {
+				cells[cell]++
+			}
+		}
+		if cells[CellHead] != 1 || cells[CellFood] != 1 {
+			t.Errorf("reset %d: %d heads and %d foods, want one of each", i, cells[CellHead], cells[CellFood])
+		}
+		if head := res.Info.Head; res.Observation[head.Y][head.X] != CellHead {
+			t.Errorf("reset %d: the head %v isn't on the observation", i, head)
+		}
+	}
+}
+
+func TestServeClose(t *testing.T) {
+	responses := session(t, NewEnv(engine.DefaultRules(), 1), `{"cmd":"reset"}`, `{"cmd":"close"}`, `{"cmd":"reset"}`)
+	if len(responses) != 1 {
+		t.Errorf("got %d responses, want only the one before the close", len(responses))
+	}
+}
+
+func TestStepDeath(t *testing.T) {
+	env := NewEnv(engine.DefaultRules(), 1)
+	if _, err := env.Reset(Options{Width: 6, Height: 4}); err != nil {
+		t.Fatal(err)
+	}
+	var res Result
+	var err error
+	for i := 0; !res.Done; i++ {
+		if i > 10 {
+			t.Fatal("the snake going up never hit the wall")
+		}
+		if res, err = env.Step(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if res.Reward != DeathReward || res.Info.Cause != "hit wall" || res.Truncated {
+		t.Errorf("got reward %v, cause %q and truncated %v, want %v, %q and false", res.Reward, res.Info.Cause, res.Truncated, float64(DeathReward), "hit wall")
+	}
+	if _, err := env.Step(0); err != ErrNotReset {
+		t.Errorf("stepping a finished game: got %v, want %v", err, ErrNotReset)
+	}
+}
+
+func TestStepReward(t *testing.T) {
+	env := NewEnv(engine.DefaultRules(), 1)
+	if _, err := env.Reset(Options{Width: 8, Height: 6}); err != nil {
+		t.Fatal(err)
+	}
+	bot := control.Pathfinder{}
+	score := 0
+	for i := 0; i < 500 && score < 3; i++ {
+		direction := control.NewView(env.state, 0).Direction() // Keeping straight when the bot doesn't turn
+		if turns := bot.Turns(control.NewView(env.state, 0)); len(turns) > 0 {
+			direction = turns[0]
+		}
+		action := 0
+		for a, d := range Actions {
+			if d == direction {
+				action = a
+			}
+		}
+		res, err := env.Step(action)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Done {
+			t.Fatalf("the bot died at tick %d", res.Info.Tick)
+		}
+		if want := float64(res.Info.Score - score); res.Reward != want {
+			t.Fatalf("tick %d: reward %v, want the %v points scored", res.Info.Tick, res.Reward, want)
+		}
+		score = res.Info.Score
+	}
+	if score < 3 {
+		t.Errorf("the bot scored %d, the rewards weren't tested", score)
+	}
+}
+
+func TestStepTruncated(t *testing.T) {
+	// Going round in a square on a board wrapping around, the snake soon stops finding food
+	env := NewEnv(engine.DefaultRules(), 1)
+	wrap := true
+	if _, err := env.Reset(Options{Width: 4, Height: 4, Wrap: &wrap}); err != nil {
+		t.Fatal(err)
+	}
+	square := []int{1, 2, 0, 3}
+	var res Result
+	var err error
+	for i := 0; !res.Truncated; i++ {
+		if i > 1000 {
+			t.Fatal("the game was never truncated")
+		}
+		if res, err = env.Step(square[i%len(square)]); err != nil {
+			t.Fatal(err)
+		}
+		if res.Done {
+			t.Fatalf("the snake died at tick %d", res.Info.Tick)
+		}
+	}
+	if res.Info.Tick < env.stallLimit() {
+		t.Errorf("truncated at tick %d, before the %d moves without eating", res.Info.Tick, env.stallLimit())
+	}
+	if _, err := env.Step(0); err != ErrNotReset {
+		t.Errorf("stepping a truncated game: got %v, want %v", err, ErrNotReset)
+	}
+}
+
+func TestObserveFoods(t *testing.T) {
+	rules := engine.DefaultRules()
+	rules.Foods = []food.Type{
+		{Kind: "apple", Weight: 1, Points: 1},
+		{Kind: "crumb", Weight: 1},
+		{Kind: "rotten", Weight: 1, Points: -2},
+		{Kind: "poison", Weight: 1, Deadly: true},
+	}
+	env := NewEnv(rules, 1)
+	if _, err := env.Reset(Options{Width: 8, Height: 6}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[vars.Point]int{{X: 0, Y: 0}: CellFood, {X: 1, Y: 0}: CellFood, {X: 2, Y: 0}: CellPenalty, {X: 3, Y: 0}: CellPoison}
+	env.state.Foods = []food.Food{
+		{Position: vars.Point{X: 0, Y: 0}, Kind: "apple"},
+		{Position: vars.Point{X: 1, Y: 0}, Kind: "crumb"},
+		{Position: vars.Point{X: 2, Y: 0}, Kind: "rotten"},
+		{Position: vars.Point{X: 3, Y: 0}, Kind: "poison", Expires: 10},
+	}
+	grid := env.observe()
+	for p, cell := range want {
+		if grid[p.Y][p.X] != cell {
+			t.Errorf("the food on %v is observed as %d, want %d", p, grid[p.Y][p.X], cell)
+		}
+	}
+}
+
+func TestActionsCopied(t *testing.T) {
+	Actions[0] = vars.Point{}
+	defer func() { Actions[0] = control.Directions[0] }()
+	if control.Directions[0] == (vars.Point{}) {
+		t.Error("changing the actions changed the directions of the bots")
+	}
+}
@@ -0,0 +1,314 @@
+// Package level describes the arenas a game can be played in: the walls, where the snake
+// starts and where the food may appear. Levels are drawn as text grids in JSON files
+package level
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"GoSnake/vars"
+)
+
+// The characters of a level grid
+const (
+	Empty  = '.' // A free cell
+	Wall   = '#' // A wall, hitting it ends the game
+	Spawn  = 'S' // Where the snake starts, exactly one per level
+	NoFood = '-' // A free cell where food never appears
+)
+
+// The digits of a level grid are portals, the two cells with the same digit are linked
+const maxPortals = 10
+
+// Portal links two cells, the snake entering one comes out of the other going the same way
+type Portal struct {
+	A vars.Point `json:"a"` // One end of the portal
+	B vars.Point `json:"b"` // The other end
+}
+
+// Exit returns the other end of the portal when a cell is one of its ends
+func (p Portal) Exit(cell vars.Point) (vars.Point, bool) {
+	switch cell {
+	case p.A:
+		return p.B, true
+	case p.B:
+		return p.A, true
+	}
+	return vars.Point{}, false
+}
+
+// Level is an arena with walls, a spawn point and cells the food avoids
+type Level struct {
+	Name      string       `json:"name"`              // The name shown to the player
+	Width     int          `json:"width"`             // The number of cells in a row
+	Height    int          `json:"height"`            // The number of cells in a column
+	Wrap      bool         `json:"wrap"`              // Whether the snake goes through the edges of the board
+	Walls     []vars.Point `json:"walls"`             // The wall cells
+	Spawn     vars.Point   `json:"spawn"`             // The cell the snake starts on
+	Direction vars.Point   `json:"direction"`         // The direction the snake starts moving in
+	NoFood    []vars.Point `json:"no_food,omitempty"` // The free cells where food never appears
+	Portals   []Portal     `json:"portals,omitempty"` // The portals, in the order of their digits
+}
+
+// file is the format of a level on disk, the grid is easier to draw and read than a list of cells
+type file struct {
+	Name      string   `json:"name"`      // The name shown to the player
+	Wrap      bool     `json:"wrap"`      // Whether the snake goes through the edges of the board
+	Direction string   `json:"direction"` // "up", "down", "left" or "right"
+	Map       []string `json:"map"`       // The rows of the grid, top first
+}
+
+// directions are the names of the directions the snake can start in
+var directions = map[string]vars.Point{
+	"up":    {X: 0, Y: -1},
+	"down":  {X: 0, Y: 1},
+	"left":  {X: -1, Y: 0},
+	"right": {X: 1, Y: 0},
+}
+
+// New creates an empty level with the snake starting in the middle, moving right
+func New(name string, width, height int) *Level {
+	return &Level{
+		Name:      name,
+		Width:     width,
+		Height:    height,
+		Spawn:     vars.Point{X: width / 2, Y: height / 2},
+		Direction: vars.Point{X: 1, Y: 0},
+	}
+}
+
+// Load reads and validates the level at the given path
+func Load(path string) (*Level, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	l, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Parse reads and validates a level
+func Parse(data []byte) (*Level, error) {
+	var f file
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&f); err != nil {
+		return nil, err
+	}
+
+	direction, ok := directions[f.Direction]
+	if !ok {
+		return nil, fmt.Errorf("unknown direction %q, expected up, down, left or right", f.Direction)
+	}
+	if len(f.Map) == 0 {
+		return nil, errors.New("the map is empty")
+	}
+
+	l := &Level{Name: f.Name, Width: len(f.Map[0]), Height: len(f.Map), Wrap: f.Wrap, Direction: direction}
+	spawns := 0
+	var ends [maxPortals][]vars.Point
+	for y, row := range f.Map {
+		if len(row) != l.Width {
+			return nil, fmt.Errorf("map row %d is %d cells long, expected %d like the first row", y+1, len(row), l.Width)
+		}
+		for x, c := range []byte(row) {
+			p := vars.Point{X: x, Y: y}
+			switch c {
+			case Empty:
+			case Wall:
+				l.Walls = append(l.Walls, p)
+			case NoFood:
+				l.NoFood = append(l.NoFood, p)
+			case Spawn:
+				l.Spawn = p
+				spawns++
+			case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+				ends[c-'0'] = append(ends[c-'0'], p)
+			default:
+				return nil, fmt.Errorf("map row %d: unknown cell %q at column %d", y+1, c, x+1)
+			}
+		}
+	}
+	for digit, cells := range ends {
+		switch len(cells) {
+		case 0:
+		case 2:
+			l.Portals = append(l.Portals, Portal{A: cells[0], B: cells[1]})
+		default:
+			return nil, fmt.Errorf("portal %d must have exactly two ends, found %d", digit, len(cells))
+		}
+	}
+	if spawns != 1 {
+		return nil, fmt.Errorf("the map must have exactly one spawn %q, found %d", Spawn, spawns)
+	}
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Save writes the level at the given path as a grid
+func Save(path string, l *Level) error {
+	data, err := json.MarshalIndent(file{Name: l.Name, Wrap: l.Wrap, Direction: directionName(l.Direction), Map: l.Grid()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Grid returns the rows of the level as drawn in a level file
+func (l *Level) Grid() []string {
+	rows := make([][]byte, l.Height)
+	for y := range rows {
+		rows[y] = bytes.Repeat([]byte{Empty}, l.Width)
+	}
+	for _, p := range l.Walls {
+		rows[p.Y][p.X] = Wall
+	}
+	for _, p := range l.NoFood {
+		rows[p.Y][p.X] = NoFood
+	}
+	for i, portal := range l.Portals {
+		rows[portal.A.Y][portal.A.X] = byte('0' + i)
+		rows[portal.B.Y][portal.B.X] = byte('0' + i)
+	}
+	rows[l.Spawn.Y][l.Spawn.X] = Spawn
+
+	grid := make([]string, l.Height)
+	for y, row := range rows {
+		grid[y] = string(row)
+	}
+	return grid
+}
+
+// Validate checks the level can be played: the snake can start and there's somewhere to put the food
+func (l *Level) Validate() error {
+	board := l.Board()
+	if l.Width < 2 || l.Height < 2 {
+		return fmt.Errorf("the level must be at least 2x2 cells, got %dx%d", l.Width, l.Height)
+	}
+	for _, p := range append(append([]vars.Point{l.Spawn}, l.Walls...), l.NoFood...) {
+		if !board.Contains(p) {
+			return fmt.Errorf("cell %d,%d is outside the %dx%d level", p.X, p.Y, l.Width, l.Height)
+		}
+	}
+	if len(l.Portals) > maxPortals {
+		return fmt.Errorf("a level has at most %d portals, got %d", maxPortals, len(l.Portals))
+	}
+	seen := map[vars.Point]bool{}
+	for _, portal := range l.Portals {
+		for _, end := range []vars.Point{portal.A, portal.B} {
+			if !board.Contains(end) || l.IsWall(end) || end == l.Spawn || seen[end] {
+				return fmt.Errorf("portal end %d,%d must be a free cell of the level used once", end.X, end.Y)
+			}
+			seen[end] = true
+		}
+	}
+	if directionName(l.Direction) == "" {
+		return fmt.Errorf("invalid direction %d,%d", l.Direction.X, l.Direction.Y)
+	}
+	if l.IsWall(l.Spawn) {
+		return errors.New("the spawn is in a wall")
+	}
+	if ahead := board.Neighbor(l.Spawn, l.Direction); !board.Contains(ahead) || l.IsWall(ahead) {
+		return errors.New("the snake would hit a wall on its first move")
+	}
+	if len(l.Walls)+len(l.NoFood)+2*len(l.Portals)+1 >= l.Width*l.Height {
+		return errors.New("there's no cell left for the food")
+	}
+	return nil
+}
+
+// Clone returns a copy of the level which can be changed without changing the original
+func (l *Level) Clone() *Level {
+	c := *l
+	c.Walls = append([]vars.Point(nil), l.Walls...)
+	c.NoFood = append([]vars.Point(nil), l.NoFood...)
+	c.Portals = append([]Portal(nil), l.Portals...)
+	return &c
+}
+
+// Set changes a cell to a wall, a free cell, a cell without food or the spawn, with the characters of the grid.
+// Moving the spawn leaves a free cell where it was, changing an end of a portal removes the whole portal
+func (l *Level) Set(p vars.Point, cell byte) {
+	if !l.Board().Contains(p) {
+		return
+	}
+	l.Walls = remove(l.Walls, p)
+	l.NoFood = remove(l.NoFood, p)
+	portals := l.Portals[:0:0]
+	for _, portal := range l.Portals {
+		if _, ok := portal.Exit(p); !ok {
+			portals = append(portals, portal)
+		}
+	}
+	l.Portals = portals
+	switch cell {
+	case Wall:
+		l.Walls = append(l.Walls, p)
+	case NoFood:
+		l.NoFood = append(l.NoFood, p)
+	case Spawn:
+		l.Spawn = p
+	}
+}
+
+// Board returns the board the level is played on
+func (l *Level) Board() vars.Board {
+	return vars.Board{Width: l.Width, Height: l.Height, Wrap: l.Wrap}
+}
+
+// IsWall reports whether a cell is a wall
+func (l *Level) IsWall(p vars.Point) bool {
+	return contains(l.Walls, p)
+}
+
+// AllowsFood reports whether food may appear on a cell, never on a portal where it could only be eaten coming out
+func (l *Level) AllowsFood(p vars.Point) bool {
+	if contains(l.Walls, p) || contains(l.NoFood, p) {
+		return false
+	}
+	for _, portal := range l.Portals {
+		if _, ok := portal.Exit(p); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// directionName returns the name of a direction, empty if it isn't one
+func directionName(d vars.Point) string {
+	for name, direction := range directions {
+		if direction == d {
+			return name
+		}
+	}
+	return ""
+}
+
+// contains reports whether a point is in a list
+func contains(points []vars.Point, p vars.Point) bool {
+	for _, q := range points {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// remove returns a list without a point
+func remove(points []vars.Point, p vars.Point) []vars.Point {
+	kept := points[:0:0]
+	for _, q := range points {
+		if q != p {
+			kept = append(kept, q)
+		}
+	}
+	return kept
+}
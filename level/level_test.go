@@ -0,0 +1,181 @@
+package level
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"GoSnake/vars"
+)
+
+// tiny is a small level using every kind of cell
+const tiny = `{
+	"name": "Tiny",
+	"wrap": true,
+	"direction": "down",
+	"map": [
+		"#####",
+		"#S-0#",
+		"#.1.#",
+		"#0.1#",
+		"#####"
+	]
+}`
+
+func TestParse(t *testing.T) {
+	got, err := Parse([]byte(tiny))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var walls []vars.Point
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			if x == 0 || y == 0 || x == 4 || y == 4 {
+				walls = append(walls, vars.Point{X: x, Y: y})
+			}
+		}
+	}
+	want := &Level{
+		Name:      "Tiny",
+		Width:     5,
+		Height:    5,
+		Wrap:      true,
+		Walls:     walls,
+		Spawn:     vars.Point{X: 1, Y: 1},
+		Direction: vars.Point{X: 0, Y: 1},
+		NoFood:    []vars.Point{{X: 2, Y: 1}},
+		Portals:   []Portal{{A: vars.Point{X: 3, Y: 1}, B: vars.Point{X: 1, Y: 3}}, {A: vars.Point{X: 2, Y: 2}, B: vars.Point{X: 3, Y: 3}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if grid := got.Grid(); !reflect.DeepEqual(grid, []string{"#####", "#S-0#", "#.1.#", "#0.1#", "#####"}) {
+		t.Errorf("the grid of the level is %q", grid)
+	}
+	if !got.IsWall(vars.Point{X: 0, Y: 2}) || got.IsWall(vars.Point{X: 1, Y: 2}) {
+		t.Errorf("the walls of the level are wrong")
+	}
+	if got.AllowsFood(vars.Point{X: 2, Y: 1}) || got.AllowsFood(vars.Point{X: 4, Y: 1}) || !got.AllowsFood(vars.Point{X: 1, Y: 2}) {
+		t.Errorf("the cells allowing food are wrong")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"not JSON", `{"map":`, "unexpected EOF"},
+		{"unknown field", `{"direction":"up","map":["S."],"size":3}`, `unknown field "size"`},
+		{"unknown direction", `{"direction":"north","map":["S."]}`, `unknown direction "north"`},
+		{"no direction", `{"map":["S."]}`, `unknown direction ""`},
+		{"empty map", `{"direction":"up","map":[]}`, "the map is empty"},
+		{"ragged rows", `{"direction":"right","map":["S..","..",".."]}`, "map row 2 is 2 cells long, expected 3"},
+		{"unknown cell", `{"direction":"right","map":["S..",".x.","..."]}`, "map row 2: unknown cell 'x' at column 2"},
+		{"no spawn", `{"direction":"right","map":["...","..."]}`, "exactly one spawn 'S', found 0"},
+		{"two spawns", `{"direction":"right","map":["S..","..S"]}`, "exactly one spawn 'S', found 2"},
+		{"portal with one end", `{"direction":"right","map":["S.3","..."]}`, "portal 3 must have exactly two ends, found 1"},
+		{"portal with three ends", `{"direction":"right","map":["S.1","1.1"]}`, "portal 1 must have exactly two ends, found 3"},
+		{"too small", `{"direction":"right","map":["S."]}`, "at least 2x2"},
+		{"facing a wall", `{"direction":"left","map":["#S.","..."]}`, "hit a wall on its first move"},
+		{"facing the edge", `{"direction":"up","map":["S..","..."]}`, "hit a wall on its first move"},
+		{"no room for food", `{"direction":"right","map":["S-","--"]}`, "no cell left for the food"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	l, err := Parse([]byte(tiny))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "tiny.json")
+	if err := Save(path, l); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, l) {
+		t.Errorf("loaded %+v, want %+v", got, l)
+	}
+}
+
+func TestShippedLevels(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "levels", "*.json"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no levels found: %v", err)
+	}
+	for _, path := range paths {
+		if _, err := Load(path); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNewSaveLoad(t *testing.T) {
+	l := New("Drawn", 8, 6)
+	l.Wrap = true
+	l.Set(vars.Point{X: 1, Y: 1}, Wall)
+	l.Set(vars.Point{X: 2, Y: 1}, NoFood)
+	l.Set(vars.Point{X: 6, Y: 4}, Spawn)
+	l.Portals = []Portal{{A: vars.Point{X: 7, Y: 0}, B: vars.Point{X: 0, Y: 5}}}
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "drawn.json")
+	if err := Save(path, l); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, l) {
+		t.Errorf("loaded %+v, want %+v", got, l)
+	}
+}
+
+func TestSet(t *testing.T) {
+	l := New("Edited", 6, 4)
+	l.Set(vars.Point{X: 0, Y: 0}, Wall)
+	l.Portals = []Portal{{A: vars.Point{X: 1, Y: 1}, B: vars.Point{X: 5, Y: 3}}}
+	original := l.Clone()
+
+	l.Set(vars.Point{X: 1, Y: 0}, Wall)
+	l.Set(vars.Point{X: 1, Y: 0}, NoFood)
+	l.Set(vars.Point{X: 0, Y: 0}, Empty)
+	l.Set(vars.Point{X: 9, Y: 9}, Wall) // Off the level, ignored
+	l.Set(vars.Point{X: 4, Y: 3}, Spawn)
+	l.Set(vars.Point{X: 5, Y: 3}, Empty) // Removes the portal
+	if len(l.Walls) != 0 || !reflect.DeepEqual(l.NoFood, []vars.Point{{X: 1, Y: 0}}) || l.Spawn != (vars.Point{X: 4, Y: 3}) || len(l.Portals) != 0 {
+		t.Errorf("got walls %v, cells without food %v, the spawn %v and portals %v", l.Walls, l.NoFood, l.Spawn, l.Portals)
+	}
+	if len(original.Walls) != 1 || len(original.NoFood) != 0 || original.Spawn != (vars.Point{X: 3, Y: 2}) || len(original.Portals) != 1 {
+		t.Errorf("editing the level changed its clone: %+v", original)
+	}
+}
+
+func TestPortalExit(t *testing.T) {
+	p := Portal{A: vars.Point{X: 1, Y: 2}, B: vars.Point{X: 7, Y: 0}}
+	if exit, ok := p.Exit(p.A); !ok || exit != p.B {
+		t.Errorf("entering %v comes out of %v, want %v", p.A, exit, p.B)
+	}
+	if exit, ok := p.Exit(p.B); !ok || exit != p.A {
+		t.Errorf("entering %v comes out of %v, want %v", p.B, exit, p.A)
+	}
+	if _, ok := p.Exit(vars.Point{X: 1, Y: 1}); ok {
+		t.Errorf("a cell next to the portal is an end of it")
+	}
+}
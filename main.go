@@ -1,23 +1,87 @@
 package main
 
 import (
+	"flag"
 	"log"
-	"math/rand"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten"
 	"github.com/hajimehoshi/ebiten/audio"
 
-	"GoSnake/food"
+	"GoSnake/cli"
+	"GoSnake/config"
+	"GoSnake/control"
+	"GoSnake/event"
 	"GoSnake/game"
+	"GoSnake/netplay"
+	"GoSnake/replay"
 	"GoSnake/sound"
-	"GoSnake/vars"
 )
 
 // main is the entry point of the application
 func main() {
-	// Seed the random number generator
-	rand.Seed(time.Now().UnixNano())
+	// Run the commands that don't need a window
+	if code, ok := cli.Run(os.Args[1:]); ok {
+		os.Exit(code)
+	}
+
+	// Parse the command line, a fixed seed replays the same game
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed of the first game")
+	replayPath := flag.String("replay", "", "replay file to watch instead of playing")
+	configPath := flag.String("config", config.Path, "configuration file")
+	width := flag.Int("width", 0, "width of the board in cells, overrides the configuration")
+	height := flag.Int("height", 0, "height of the board in cells, overrides the configuration")
+	wrap := flag.Bool("wrap", false, "let the snake go through the edges of the board, overrides the configuration")
+	levelPath := flag.String("level", "", "level file to play in, overrides the configuration")
+	players := flag.Int("players", 0, "number of snakes on the board, 2 for a game between two players, overrides the configuration")
+	connect := flag.String("connect", "", "address of a server to play on, such as "+netplay.DefaultAddr)
+	watch := flag.String("watch", "", "address of a broadcast or server to watch, such as "+netplay.DefaultWatchAddr)
+	broadcast := flag.String("broadcast", "", "address to broadcast the game on for spectators, such as "+netplay.DefaultWatchAddr)
+	bots := flag.String("bots", "", "bot playing each player, separated by commas and empty for a person: "+strings.Join(control.Bots, ", "))
+	tile := flag.Int("tile", 0, "size of a cell in pixels, overrides the configuration")
+	flag.Parse()
+
+	// Load the configuration and apply the command line over it
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *width > 0 {
+		cfg.Rules.Width = *width
+	}
+	if *height > 0 {
+		cfg.Rules.Height = *height
+	}
+	if *wrap {
+		cfg.Rules.Wrap = true
+	}
+	if *levelPath != "" {
+		cfg.Rules.Level = *levelPath
+	}
+	if *players > 0 {
+		cfg.Rules.Players = *players
+	}
+	if *tile > 0 {
+		cfg.Visuals.TileSize = *tile
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	bindings, err := game.NewBindings(cfg.Controls)
+	if err != nil {
+		log.Fatalf("%s: %v", *configPath, err)
+	}
+	rival, err := game.NewBindings(cfg.PlayerTwo)
+	if err != nil {
+		log.Fatalf("%s: player_two: %v", *configPath, err)
+	}
+	rules, err := cfg.EngineRules()
+	if err != nil {
+		log.Fatal(err)
+	}
+	board := rules.Board
 
 	// Create a new audio context
 	audioCtx, err := audio.NewContext(44100)
@@ -26,31 +90,107 @@ func main() {
 	}
 
 	// Create a new audio manager
-	audioManager := sound.NewAudioManager(audioCtx)
+	audioManager := sound.NewAudioManager(audioCtx, cfg.Audio)
+
+	// Create the event bus and subscribe the features reacting to the game
+	bus := event.NewBus()
+	bus.Subscribe(audioManager.HandleEvent)
+	bus.Subscribe(game.RecordScore)
 
 	// Initialize game components
-	snake := game.NewSnake()
-	food := food.NewFood()
-	renderer := game.NewRenderer()
-	logic := game.NewGameLogic(audioManager)
-	gameStartManager := game.NewGameStartManager()
-	gamePauseManager := game.NewGamePauseManager()
+	renderer := game.NewRenderer(cfg.Visuals)
+	logic := game.NewGameLogic()
+
+	// Let bots play if asked to
+	var controllers []control.Controller
+	if *bots != "" {
+		for _, name := range strings.Split(*bots, ",") {
+			if name == "" {
+				controllers = append(controllers, nil)
+				continue
+			}
+			bot, err := control.NewBot(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+			controllers = append(controllers, bot)
+		}
+	}
+
+	// The people playing here share the keyboard and the gamepads, there are controls for two of them
+	if *replayPath == "" && *connect == "" && *watch == "" {
+		people := rules.PlayerCount()
+		for i := 0; i < len(controllers) && i < rules.PlayerCount(); i++ {
+			if controllers[i] != nil {
+				people--
+			}
+		}
+		if people > game.MaxPlayers {
+			log.Fatalf("%d people can't play on one machine, at most %d can: let bots play the other snakes with --bots", people, game.MaxPlayers)
+		}
+	}
 
 	// Create a new game instance
-	g := game.NewGame(snake, food, renderer, logic, gameStartManager, gamePauseManager, audioManager)
+	g := game.NewGame(rules, bindings, rival, game.NewGamepads(cfg.Gamepad), *configPath, *seed, renderer, logic, bus)
+	if controllers != nil {
+		g.SetBots(controllers)
+	}
+
+	// Watch a replay if one was given
+	if *replayPath != "" {
+		r, err := replay.Read(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		g.PlayReplay(r)
+	}
+
+	// Play on a server if one was given
+	if *connect != "" {
+		client, err := netplay.Dial(*connect)
+		if err != nil {
+			log.Fatal(err)
+		}
+		g.Connect(client)
+	}
+
+	// Watch a game if one was given
+	if *watch != "" {
+		client, err := netplay.Watch(*watch)
+		if err != nil {
+			log.Fatal(err)
+		}
+		g.Connect(client)
+	}
+
+	// Broadcast the game to spectators if asked to
+	if *broadcast != "" {
+		b, err := netplay.Broadcast(*broadcast)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Broadcasting on %s", b.Addr())
+		g.Broadcast(b)
+	}
 
 	// Create a new game manager
-	gameManager := game.NewGameManager(g, gameStartManager, gamePauseManager)
+	gameManager := game.NewGameManager(g)
 
 	// Set window size and title
-	ebiten.SetWindowSize(vars.ScreenWidth*2, vars.ScreenHeight*2)
+	screenWidth, screenHeight := renderer.ScreenSize(board)
+	ebiten.SetWindowSize(screenWidth*2, screenHeight*2)
 	ebiten.SetWindowTitle("GoSnake")
 
 	// Run the game
-	if err := ebiten.RunGame(gameManager); err != nil {
+	if err := ebiten.RunGame(gameManager); err != nil && err != game.ErrQuit {
 		log.Fatal(err)
 	}
 
+	// Save the game in progress so it can be continued
+	if err := gameManager.Close(); err != nil {
+		log.Printf("Error saving the game: %v", err)
+	}
+
 	// Close the audio manager
 	audioManager.Close()
 }
@@ -0,0 +1,190 @@
+package netplay
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"GoSnake/engine"
+)
+
+// outboxSize is the number of messages waiting for a spectator before it's considered too slow and dropped
+const outboxSize = 64
+
+// errNotSpectator is sent to players connecting to a broadcast
+var errNotSpectator = errors.New("this game only takes spectators")
+
+// Broadcaster shows a game played here to the spectators connected to it. Spectators can connect at any
+// time, they're shown the game being played straight away. Sending never blocks the game, a spectator
+// who can't keep up is disconnected
+type Broadcaster struct {
+	listener net.Listener // Where the spectators connect
+
+	mu         sync.Mutex
+	spectators map[*spectator]bool // The spectators connected
+	welcome    []byte              // The welcome of the game being played, nil before the first one
+	snapshot   []byte              // The last snapshot of the game being played
+	closed     bool                // Whether Close was called
+}
+
+// spectator is a client watching a broadcast
+type spectator struct {
+	conn   net.Conn
+	outbox chan []byte // The messages waiting to be sent, closed when the spectator leaves
+}
+
+// Broadcast starts listening for spectators on a TCP address
+func Broadcast(addr string) (*Broadcaster, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	b := &Broadcaster{listener: listener, spectators: map[*spectator]bool{}}
+	go b.accept()
+	return b, nil
+}
+
+// Addr returns the address the spectators connect to
+func (b *Broadcaster) Addr() net.Addr {
+	return b.listener.Addr()
+}
+
+// Start shows a new game to the spectators, or a game played again or continued from a save
+func (b *Broadcaster) Start(state engine.State) {
+	rules := state.Rules
+	welcome := encode(Message{Kind: KindWelcome, Player: -1, Rules: &rules, Seed: state.Seed})
+	snapshot := encode(Message{Kind: KindSnapshot, Snapshot: NewSnapshot(state)})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.welcome, b.snapshot = welcome, snapshot
+	for s := range b.spectators {
+		b.send(s, welcome)
+		b.send(s, snapshot)
+	}
+}
+
+// Publish shows the board to the spectators after a move
+func (b *Broadcaster) Publish(state engine.State) {
+	snapshot := encode(Message{Kind: KindSnapshot, Snapshot: NewSnapshot(state)})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.welcome == nil {
+		return // Start wasn't called, the spectators couldn't make sense of it
+	}
+	b.snapshot = snapshot
+	for s := range b.spectators {
+		b.send(s, snapshot)
+	}
+}
+
+// Close stops the broadcast and disconnects the spectators
+func (b *Broadcaster) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for s := range b.spectators {
+		b.remove(s)
+	}
+	return b.listener.Close()
+}
+
+// accept takes the new spectators until the broadcast is closed
+func (b *Broadcaster) accept() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+// handle lets a spectator in and waits for it to leave, spectators don't send anything after their hello
+func (b *Broadcaster) handle(conn net.Conn) {
+	decoder := json.NewDecoder(conn)
+	hello, err := readHello(conn, decoder)
+	if err == nil && !hello.Spectate {
+		err = errNotSpectator
+	}
+	if err != nil {
+		log.Printf("Refused spectator %s: %v", conn.RemoteAddr(), err)
+		conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		conn.Write(encode(Message{Kind: KindError, Error: err.Error()}))
+		conn.Close()
+		return
+	}
+
+	s := &spectator{conn: conn, outbox: make(chan []byte, outboxSize)}
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		conn.Close()
+		return
+	}
+	b.spectators[s] = true
+	if b.welcome != nil {
+		b.send(s, b.welcome)
+		b.send(s, b.snapshot)
+	}
+	b.mu.Unlock()
+	log.Printf("Spectator joined from %s", conn.RemoteAddr())
+
+	go s.write()
+	for {
+		var m Message
+		if err := decoder.Decode(&m); err != nil {
+			break
+		}
+	}
+	b.mu.Lock()
+	b.remove(s)
+	b.mu.Unlock()
+}
+
+// send queues a message for a spectator, dropping the spectator if it's too far behind.
+// The lock must be held
+func (b *Broadcaster) send(s *spectator, data []byte) {
+	if !b.spectators[s] {
+		return // Dropped while sending the previous message
+	}
+	select {
+	case s.outbox <- data:
+	default:
+		log.Printf("Spectator %s is too slow, disconnecting it", s.conn.RemoteAddr())
+		b.remove(s)
+	}
+}
+
+// remove disconnects a spectator, the lock must be held
+func (b *Broadcaster) remove(s *spectator) {
+	if !b.spectators[s] {
+		return
+	}
+	delete(b.spectators, s)
+	close(s.outbox)
+	s.conn.Close()
+}
+
+// write sends the queued messages to the spectator until it leaves
+func (s *spectator) write() {
+	for data := range s.outbox {
+		s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if _, err := s.conn.Write(data); err != nil {
+			s.conn.Close() // Its reader fails and removes it
+		}
+	}
+}
+
+// encode returns a message as a line of JSON
+func encode(m Message) []byte {
+	data, err := json.Marshal(m)
+	if err != nil {
+		panic(err) // Messages only hold values that can be encoded
+	}
+	return append(data, '\n')
+}
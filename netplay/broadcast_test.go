@@ -0,0 +1,68 @@
+package netplay
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"GoSnake/engine"
+)
+
+func TestBroadcast(t *testing.T) {
+	b, err := Broadcast("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	// A spectator connecting before the game starts and one connecting after a few moves both see the board
+	early, err := Watch(b.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer early.Close()
+
+	state := engine.NewState(rules, 3)
+	b.Start(state)
+	for i := 0; i < 3; i++ {
+		state, _ = engine.Step(state, engine.Input{})
+		b.Publish(state)
+	}
+	late, err := Watch(b.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer late.Close()
+
+	for _, c := range []*Client{early, late} {
+		got := wait(t, c, "the third move", func(s engine.State) bool { return s.Tick == state.Tick })
+		if c.Player() != -1 || !reflect.DeepEqual(got.Scores, state.Scores) || !reflect.DeepEqual(got.Foods, state.Foods) {
+			t.Errorf("the spectator plays %d and sees scores %v and foods %v, want -1, %v and %v", c.Player(), got.Scores, got.Foods, state.Scores, state.Foods)
+		}
+		for i, snake := range got.Snakes {
+			if !reflect.DeepEqual(snake.Body, state.Snakes[i].Body) {
+				t.Errorf("the spectator sees snake %d on %v, want %v", i, snake.Body, state.Snakes[i].Body)
+			}
+		}
+	}
+}
+
+func TestBroadcastRefusesPlayers(t *testing.T) {
+	b, err := Broadcast("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	c, err := Dial(b.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for deadline := time.Now().Add(5 * time.Second); c.Err() == nil && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := c.Err(); err == nil || !strings.Contains(err.Error(), errNotSpectator.Error()) {
+		t.Errorf("got error %v, want one containing %q", err, errNotSpectator)
+	}
+}
@@ -0,0 +1,145 @@
+package netplay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"GoSnake/engine"
+	"GoSnake/vars"
+)
+
+// dialTimeout is how long connecting to a server may take
+const dialTimeout = 5 * time.Second
+
+// Client is a player connected to a server, it keeps the game as last sent by the server
+type Client struct {
+	conn    net.Conn
+	encoder *json.Encoder
+
+	mu      sync.Mutex
+	player  int          // The snake the client controls
+	state   engine.State // The game as last seen
+	started bool         // Whether a game was received
+	err     error        // Why the connection ended, nil while it's open
+}
+
+// Dial connects to a server, the client is given a snake once the server starts a game
+func Dial(addr string) (*Client, error) {
+	return dial(addr, false)
+}
+
+// Watch connects to a server or a broadcast as a spectator, the client sees the games without playing
+func Watch(addr string) (*Client, error) {
+	return dial(addr, true)
+}
+
+// dial connects to a server and says hello
+func dial(addr string, spectate bool) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{conn: conn, encoder: json.NewEncoder(conn), player: -1}
+	if err := c.encoder.Encode(Message{Kind: KindHello, Version: Version, Spectate: spectate}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	go c.read()
+	return c, nil
+}
+
+// Player returns the player the client controls, -1 until a game started and for a spectator
+func (c *Client) Player() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.player
+}
+
+// State returns the game as last sent by the server, ok is false until a game started.
+// The state is only good for drawing, the client doesn't run the engine
+func (c *Client) State() (state engine.State, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state, c.started
+}
+
+// Err returns why the connection ended, nil while it's open
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Turn asks the server to turn the client's snake
+func (c *Client) Turn(direction vars.Point) error {
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.encoder.Encode(Message{Kind: KindTurn, Direction: &direction})
+}
+
+// Close disconnects from the server
+func (c *Client) Close() error {
+	c.fail(errors.New("disconnected"))
+	return c.conn.Close()
+}
+
+// read applies the messages of the server until the connection ends
+func (c *Client) read() {
+	decoder := json.NewDecoder(c.conn)
+	for {
+		var m Message
+		if err := decoder.Decode(&m); err != nil {
+			c.fail(err)
+			return
+		}
+		if err := c.apply(m); err != nil {
+			c.fail(err)
+			c.conn.Close()
+			return
+		}
+	}
+}
+
+// apply updates the game with a message of the server
+func (c *Client) apply(m Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch m.Kind {
+	case KindWelcome:
+		if m.Rules == nil {
+			return errors.New("welcome without rules")
+		}
+		if err := m.Rules.Validate(); err != nil {
+			return fmt.Errorf("invalid rules: %w", err)
+		}
+		if m.Player < -1 || m.Player >= m.Rules.PlayerCount() {
+			return fmt.Errorf("invalid player %d", m.Player)
+		}
+		c.player = m.Player
+		c.state = engine.NewState(*m.Rules, m.Seed)
+		c.started = true
+	case KindSnapshot:
+		if !c.started || m.Snapshot == nil {
+			return errors.New("snapshot before the welcome")
+		}
+		if len(m.Snapshot.Snakes) != len(c.state.Snakes) || len(m.Snapshot.Scores) != len(c.state.Snakes) {
+			return errors.New("snapshot for another game")
+		}
+		m.Snapshot.Apply(&c.state)
+	case KindError:
+		return fmt.Errorf("server: %s", m.Error)
+	}
+	return nil
+}
+
+// fail remembers the first reason the connection ended
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
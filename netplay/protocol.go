@@ -0,0 +1,113 @@
+// Package netplay plays games over the network: a server runs the engine for every player and sends
+// them what happens, the clients send it their turns. A game played in a window can also be broadcast
+// to spectators. It doesn't depend on ebiten so the server runs without a display
+package netplay
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"GoSnake/engine"
+	"GoSnake/food"
+	"GoSnake/vars"
+)
+
+// Version is the version of the protocol, clients speaking another one are turned away
+const Version = 1
+
+const (
+	DefaultAddr      = "localhost:7777" // Where the server listens and the clients connect when no address is given
+	DefaultWatchAddr = "localhost:7778" // Where a game broadcasts to its spectators when no address is given
+)
+
+// The kinds of messages, each one is a line of JSON
+const (
+	KindHello    = "hello"    // The client introduces itself, it's the first message sent
+	KindWelcome  = "welcome"  // The server gives the client its snake, the rules and the seed of a new game
+	KindSnapshot = "snapshot" // The server sends the board after a move
+	KindTurn     = "turn"     // The client asks its snake to turn
+	KindError    = "error"    // The server explains why it's closing the connection
+)
+
+// Message is a line of the protocol, its kind tells which fields are set
+type Message struct {
+	Kind      string        `json:"kind"`                // What the message is about
+	Version   int           `json:"version,omitempty"`   // The version of the protocol, in a hello
+	Spectate  bool          `json:"spectate,omitempty"`  // Whether the client only watches, in a hello
+	Player    int           `json:"player"`              // The player the client controls, -1 for a spectator, in a welcome
+	Rules     *engine.Rules `json:"rules,omitempty"`     // The rules of the game, in a welcome
+	Seed      int64         `json:"seed"`                // The seed of the game, in a welcome
+	Snapshot  *Snapshot     `json:"snapshot,omitempty"`  // The board, in a snapshot
+	Direction *vars.Point   `json:"direction,omitempty"` // The direction to turn to, in a turn
+	Error     string        `json:"error,omitempty"`     // What went wrong, in an error
+}
+
+// Snapshot is what changes on the board from one move to the next, the rest comes with the welcome
+type Snapshot struct {
+	Tick     int         `json:"tick"`      // The number of moves made
+	Snakes   []snake     `json:"snakes"`    // The snake of each player
+	Foods    []food.Food `json:"foods"`     // The food on the board
+	Bonus    food.Bonus  `json:"bonus"`     // The bonus food
+	Scores   []int       `json:"scores"`    // The score of each player
+	GameOver bool        `json:"game_over"` // Whether the game is over, at most one snake is left
+	GameWon  bool        `json:"game_won"`  // Whether a player reached the winning score
+	Winner   int         `json:"winner"`    // The player who won, -1 if none did
+}
+
+// snake is the part of a snake the clients see
+type snake struct {
+	Body      []vars.Point `json:"body"`           // The body of the snake, head first
+	Direction vars.Point   `json:"direction"`      // The direction of the snake
+	Dead      bool         `json:"dead,omitempty"` // Whether the snake died while the others play on
+}
+
+// NewSnapshot takes a snapshot of a game
+func NewSnapshot(state engine.State) *Snapshot {
+	snapshot := &Snapshot{
+		Tick:     state.Tick,
+		Foods:    state.Foods,
+		Bonus:    state.Bonus,
+		Scores:   state.Scores,
+		GameOver: state.GameOver,
+		GameWon:  state.GameWon,
+		Winner:   state.Winner,
+	}
+	for _, s := range state.Snakes {
+		snapshot.Snakes = append(snapshot.Snakes, snake{Body: s.Body, Direction: s.Direction, Dead: s.Dead})
+	}
+	return snapshot
+}
+
+// Apply puts the board of the snapshot into the state of the same game
+func (s *Snapshot) Apply(state *engine.State) {
+	state.Tick = s.Tick
+	state.Snakes = make([]engine.Snake, len(s.Snakes))
+	for i, snake := range s.Snakes {
+		state.Snakes[i] = engine.Snake{Body: snake.Body, Direction: snake.Direction, Dead: snake.Dead}
+	}
+	state.Foods = s.Foods
+	state.Bonus = s.Bonus
+	state.Scores = s.Scores
+	state.GameOver = s.GameOver
+	state.GameWon = s.GameWon
+	state.Winner = s.Winner
+}
+
+// readHello reads the first message of a client and checks it speaks the same protocol
+func readHello(conn net.Conn, decoder *json.Decoder) (Message, error) {
+	conn.SetReadDeadline(time.Now().Add(helloTimeout))
+	defer conn.SetReadDeadline(time.Time{})
+	var m Message
+	if err := decoder.Decode(&m); err != nil {
+		return m, err
+	}
+	if m.Kind != KindHello {
+		return m, fmt.Errorf("expected a hello, got %q", m.Kind)
+	}
+	if m.Version != Version {
+		return m, fmt.Errorf("unsupported protocol version %d, this game speaks version %d", m.Version, Version)
+	}
+	return m, nil
+}
@@ -0,0 +1,20 @@
+package netplay
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWelcomeFields(t *testing.T) {
+	// The first player and the seed 0 are sent like any other, not left out as if they were missing
+	data, err := json.Marshal(Message{Kind: KindWelcome, Player: 0, Rules: &rules, Seed: 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"player":0`, `"seed":0`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("the welcome %s has no %s", data, field)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package netplay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"GoSnake/control"
+	"GoSnake/engine"
+	"GoSnake/event"
+	"GoSnake/vars"
+)
+
+const (
+	helloTimeout = 5 * time.Second // How long a new connection has to say hello
+	writeTimeout = time.Second     // How long a message may take to send before the client is dropped
+	startDelay   = 3 * time.Second // The time the players get to find their snake before it starts moving
+	restartDelay = 5 * time.Second // The time the players get to see the result before the next game
+)
+
+// Server runs the games of the players connected to it. It's the only one running the engine, the clients
+// only send turns and draw the snapshots they receive. The first players to connect take the snakes, a player
+// connecting after one left takes the snake left behind, even during a game. Spectators may connect at any time
+type Server struct {
+	rules    engine.Rules
+	seed     int64        // The seed of the first game, each game uses the next one
+	listener net.Listener // Where the clients connect
+	joins    chan *peer   // The clients who said hello
+	leaves   chan *peer   // The clients who disconnected
+	turns    chan turn    // The turns sent by the clients
+	done     chan struct{}
+	close    sync.Once
+
+	// Only used by the goroutine running Serve
+	seats      []*peer      // The client playing each snake, nil when a snake has no player
+	spectators []*peer      // The clients watching the games
+	state      engine.State // The game being played
+	playing    bool         // Whether a game is being played, the players are awaited otherwise
+}
+
+// peer is a client connected to the server
+type peer struct {
+	conn      net.Conn
+	encoder   *json.Encoder
+	player    int     // The snake it controls, -1 until it has one
+	spectator bool    // Whether it only watches the games
+	remote    *remote // The controller of its snake, playing the turns it sent
+}
+
+// remote is the controller of a snake played from a client, it plays the turns received since the last move
+type remote struct {
+	turns []vars.Point
+}
+
+// Turns returns the turns received since the last move
+func (r *remote) Turns(view control.View) []vars.Point {
+	turns := r.turns
+	r.turns = nil
+	return turns
+}
+
+// turn is a turn sent by a client
+type turn struct {
+	peer      *peer
+	direction vars.Point
+}
+
+// Listen starts listening for players on a TCP address, the games start once there's a player for each snake
+func Listen(addr string, rules engine.Rules, seed int64) (*Server, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		rules:    rules,
+		seed:     seed,
+		listener: listener,
+		joins:    make(chan *peer),
+		leaves:   make(chan *peer),
+		turns:    make(chan turn),
+		done:     make(chan struct{}),
+		seats:    make([]*peer, rules.PlayerCount()),
+	}, nil
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close stops the server and disconnects the players
+func (s *Server) Close() error {
+	var err error
+	s.close.Do(func() {
+		close(s.done)
+		err = s.listener.Close()
+	})
+	return err
+}
+
+// Serve plays games until the server is closed, waiting for the missing players before each one
+func (s *Server) Serve() error {
+	go s.accept()
+	defer s.disconnect()
+
+	for game := int64(0); ; game++ {
+		for s.free() >= 0 {
+			select {
+			case p := <-s.joins:
+				s.join(p)
+			case p := <-s.leaves:
+				s.leave(p)
+			case <-s.turns:
+				// No snake is moving yet
+			case <-s.done:
+				return nil
+			}
+		}
+		if !s.play(s.seed + game) {
+			return nil
+		}
+	}
+}
+
+// play runs a game until it ends and leaves the result on the players' screens for a while,
+// it returns false if the server was closed in the meantime
+func (s *Server) play(seed int64) bool {
+	s.state = engine.NewState(s.rules, seed)
+	s.playing = true
+	defer func() { s.playing = false }()
+	log.Printf("Game with seed %d started", seed)
+	for _, p := range s.seats {
+		if p != nil {
+			s.welcome(p)
+		}
+	}
+	for _, p := range s.spectators {
+		s.welcome(p)
+	}
+
+	timer := time.NewTimer(startDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if s.state.GameOver || s.state.GameWon {
+				return true
+			}
+			var events []event.Event
+			s.state, events = engine.Step(s.state, s.input())
+			s.broadcast(Message{Kind: KindSnapshot, Snapshot: NewSnapshot(s.state)})
+			for _, e := range events {
+				if died, ok := e.(event.SnakeDied); ok && len(s.state.Snakes) > 1 && !s.state.GameOver {
+					log.Printf("Player %d is out, %s", died.Player+1, died.Cause)
+				}
+			}
+			if s.state.GameOver || s.state.GameWon {
+				log.Printf("Game with seed %d over, scores %v, %s", seed, s.state.Scores, result(s.state.Winner))
+				timer.Reset(restartDelay)
+			} else {
+				timer.Reset(s.state.MoveInterval)
+			}
+		case p := <-s.joins:
+			s.join(p)
+		case p := <-s.leaves:
+			s.leave(p)
+		case t := <-s.turns:
+			if t.peer.player >= 0 && !s.state.Snakes[t.peer.player].Dead {
+				t.peer.remote.turns = append(t.peer.remote.turns, t.direction)
+			}
+		case <-s.done:
+			return false
+		}
+	}
+}
+
+// input gathers the turns of every player still in the game before a move, the snakes without a player go straight
+func (s *Server) input() engine.Input {
+	var input engine.Input
+	for i, p := range s.seats {
+		if p != nil && !s.state.Snakes[i].Dead {
+			for _, direction := range p.remote.Turns(control.NewView(s.state, i)) {
+				input.Add(i, direction)
+			}
+		}
+	}
+	return input
+}
+
+// free returns the first snake without a player, -1 if every snake has one
+func (s *Server) free() int {
+	for i, p := range s.seats {
+		if p == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// join gives a client the first snake without a player, or turns it away when there's none.
+// Spectators are always let in
+func (s *Server) join(p *peer) {
+	if p.spectator {
+		s.spectators = append(s.spectators, p)
+		log.Printf("Spectator joined from %s", p.conn.RemoteAddr())
+		if s.playing {
+			s.welcome(p)
+		}
+		return
+	}
+	seat := s.free()
+	if seat < 0 {
+		p.send(Message{Kind: KindError, Error: "the game is full"})
+		p.conn.Close()
+		return
+	}
+	s.seats[seat] = p
+	p.player = seat
+	p.remote = &remote{}
+	log.Printf("Player %d joined from %s", seat+1, p.conn.RemoteAddr())
+	if s.playing {
+		s.welcome(p)
+	}
+}
+
+// leave frees the snake of a client who disconnected, the snake goes on straight until someone takes it
+func (s *Server) leave(p *peer) {
+	if p.player >= 0 && s.seats[p.player] == p {
+		log.Printf("Player %d left", p.player+1)
+		s.seats[p.player] = nil
+	}
+	for i, spectator := range s.spectators {
+		if spectator == p {
+			s.spectators = append(s.spectators[:i], s.spectators[i+1:]...)
+		}
+	}
+	p.player = -1
+	p.conn.Close()
+}
+
+// welcome tells a player which snake is theirs and shows them the board
+func (s *Server) welcome(p *peer) {
+	rules := s.state.Rules
+	p.send(Message{Kind: KindWelcome, Player: p.player, Rules: &rules, Seed: s.state.Seed})
+	p.send(Message{Kind: KindSnapshot, Snapshot: NewSnapshot(s.state)})
+}
+
+// broadcast sends a message to every player and spectator
+func (s *Server) broadcast(m Message) {
+	for _, p := range s.seats {
+		if p != nil {
+			p.send(m)
+		}
+	}
+	for _, p := range s.spectators {
+		p.send(m)
+	}
+}
+
+// disconnect closes the connection of every player and spectator
+func (s *Server) disconnect() {
+	for i, p := range s.seats {
+		if p != nil {
+			p.conn.Close()
+			s.seats[i] = nil
+		}
+	}
+	for _, p := range s.spectators {
+		p.conn.Close()
+	}
+	s.spectators = nil
+}
+
+// accept takes the new connections until the server is closed
+func (s *Server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+			default:
+				log.Printf("Error accepting connections: %v", err)
+				s.Close()
+			}
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle reads the messages of a client, starting with its hello, and passes them to Serve
+func (s *Server) handle(conn net.Conn) {
+	p := &peer{conn: conn, encoder: json.NewEncoder(conn), player: -1}
+	decoder := json.NewDecoder(conn)
+	hello, err := readHello(conn, decoder)
+	if err != nil {
+		log.Printf("Refused %s: %v", conn.RemoteAddr(), err)
+		p.send(Message{Kind: KindError, Error: err.Error()})
+		conn.Close()
+		return
+	}
+	p.spectator = hello.Spectate
+	if !s.pass(s.joins, p) {
+		conn.Close()
+		return
+	}
+
+	for {
+		var m Message
+		if err := decoder.Decode(&m); err != nil {
+			s.pass(s.leaves, p)
+			return
+		}
+		if m.Kind == KindTurn && m.Direction != nil && isDirection(*m.Direction) {
+			select {
+			case s.turns <- turn{peer: p, direction: *m.Direction}:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// pass hands a client over to Serve, it returns false if the server was closed
+func (s *Server) pass(ch chan<- *peer, p *peer) bool {
+	select {
+	case ch <- p:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// send writes a message to the client, a client too slow to take it is disconnected
+func (p *peer) send(m Message) {
+	p.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := p.encoder.Encode(m); err != nil && !errors.Is(err, net.ErrClosed) {
+		p.conn.Close() // Its reader fails and reports it gone
+	}
+}
+
+// result describes the outcome of a game for the log
+func result(winner int) string {
+	if winner < 0 {
+		return "no winner"
+	}
+	return fmt.Sprintf("player %d won", winner+1)
+}
+
+// isDirection reports whether a point is one of the four directions
+func isDirection(p vars.Point) bool {
+	return (p.X == 0) != (p.Y == 0) && p.X*p.X+p.Y*p.Y == 1
+}
@@ -0,0 +1,152 @@
+package netplay
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"GoSnake/engine"
+	"GoSnake/event"
+	"GoSnake/vars"
+)
+
+// rules are the rules of the games in the tests, a small board and fast snakes so games end quickly
+var rules = func() engine.Rules {
+	r := engine.DefaultRules()
+	r.Board.Width, r.Board.Height = 10, 10
+	r.StartInterval, r.MinInterval = 10*time.Millisecond, 10*time.Millisecond
+	r.Players = 2
+	return r
+}()
+
+// serve starts a server on a free local port and stops it at the end of the test
+func serve(t *testing.T, rules engine.Rules, seed int64) *Server {
+	t.Helper()
+	server, err := Listen("127.0.0.1:0", rules, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// connect connects a client to a server and disconnects it at the end of the test
+func connect(t *testing.T, server *Server) *Client {
+	t.Helper()
+	c, err := Dial(server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// wait waits for the state of a client to satisfy a condition, and returns it
+func wait(t *testing.T, c *Client, what string, cond func(engine.State) bool) engine.State {
+	t.Helper()
+	for deadline := time.Now().Add(startDelay + 5*time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if err := c.Err(); err != nil {
+			t.Fatalf("waiting for %s: %v", what, err)
+		}
+		if state, ok := c.State(); ok && cond(state) {
+			return state
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+	return engine.State{}
+}
+
+func TestLoopback(t *testing.T) {
+	three := rules
+	three.Players = 3
+	tests := []struct {
+		rules engine.Rules
+		seed  int64
+		out   bool // Whether a snake dies while the others play on
+	}{
+		{rules, 5, false},
+		{rules, 0, false},
+		{three, 7, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d players seed %d", tt.rules.Players, tt.seed), func(t *testing.T) {
+			t.Parallel()
+			if out := loopback(t, tt.rules, tt.seed); out != tt.out {
+				t.Errorf("a snake died while the others played on: %v, want %v", out, tt.out)
+			}
+		})
+	}
+}
+
+// loopback plays a game on a server with a client for each player and a spectator, the second player turning
+// up on the first move and the spectator trying to turn down, and checks everyone sees it end as it does without a network. It reports whether a snake
+// died while the others played on
+func loopback(t *testing.T, rules engine.Rules, seed int64) bool {
+	server := serve(t, rules, seed)
+	clients := make([]*Client, rules.PlayerCount())
+	for i := range clients {
+		clients[i] = connect(t, server)
+	}
+	spectator, err := Watch(server.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spectator.Close()
+
+	// The clients take the snakes in the order the server accepts them, which may not be the order they dialed
+	started := func(engine.State) bool { return true }
+	players := make([]*Client, len(clients))
+	for _, c := range clients {
+		wait(t, c, "the welcome", started)
+		if p := c.Player(); p < 0 || p >= len(players) || players[p] != nil {
+			t.Fatalf("a client plays %d", p)
+		}
+		players[c.Player()] = c
+	}
+
+	// The turn reaches the server during the start delay, so it's taken on the first move
+	up := vars.Point{X: 0, Y: -1}
+	if err := players[1].Turn(up); err != nil {
+		t.Fatal(err)
+	}
+	// The spectator's turns are ignored
+	if err := spectator.Turn(vars.Point{X: 0, Y: 1}); err != nil {
+		t.Fatal(err)
+	}
+	over := func(s engine.State) bool { return s.GameOver || s.GameWon }
+	var got []engine.State
+	for _, c := range append(players, spectator) {
+		got = append(got, wait(t, c, "the end of the game", over))
+	}
+	if spectator.Player() != -1 {
+		t.Errorf("the spectator plays %d, want -1", spectator.Player())
+	}
+
+	want := engine.NewState(rules, seed)
+	var input engine.Input
+	input.Add(1, up)
+	out := false
+	for !want.GameOver && !want.GameWon {
+		var events []event.Event
+		want, events = engine.Step(want, input)
+		input = engine.Input{}
+		for _, e := range events {
+			_, died := e.(event.SnakeDied)
+			out = out || (died && !want.GameOver)
+		}
+	}
+	for i, state := range got {
+		if state.Tick != want.Tick || state.Winner != want.Winner || !reflect.DeepEqual(state.Scores, want.Scores) || !reflect.DeepEqual(state.Foods, want.Foods) {
+			t.Errorf("client %d: the game ended at tick %d with winner %d, scores %v and foods %v, want %d, %d, %v and %v",
+				i, state.Tick, state.Winner, state.Scores, state.Foods, want.Tick, want.Winner, want.Scores, want.Foods)
+		}
+		for j, snake := range state.Snakes {
+			if !reflect.DeepEqual(snake.Body, want.Snakes[j].Body) || snake.Direction != want.Snakes[j].Direction || snake.Dead != want.Snakes[j].Dead {
+				t.Errorf("client %d: snake %d ended as %+v, want %+v", i, j, snake, want.Snakes[j])
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"GoSnake/engine"
+	"GoSnake/vars"
+)
+
+const (
+	magic   = "GSNR" // The first bytes of every replay file
+	version = 1      // The version of the format written by Encode
+
+	maxRulesSize = 1 << 20 // The largest encoded rules accepted when decoding, levels make them grow with the board
+)
+
+// directions lists the directions a turn can take, a turn is stored as its index in this list
+var directions = []vars.Point{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}}
+
+// Encode writes a replay in a compact binary format: the magic, the version, the length of the rules
+// and the rules as JSON, the seed and the number of turns, then each turn as the ticks elapsed since the
+// previous turn and a byte holding the player in its high bits and the direction in the two low bits
+func Encode(w io.Writer, r *Replay) error {
+	rules, err := json.Marshal(r.Rules)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, binary.MaxVarintLen64)
+	bw.WriteString(magic)
+	bw.Write(buf[:binary.PutUvarint(buf, version)])
+	bw.Write(buf[:binary.PutUvarint(buf, uint64(len(rules)))])
+	bw.Write(rules)
+	bw.Write(buf[:binary.PutVarint(buf, r.Seed)])
+	bw.Write(buf[:binary.PutUvarint(buf, uint64(len(r.Turns)))])
+
+	previous := 0
+	for _, turn := range r.Turns {
+		if turn.Tick < previous {
+			return fmt.Errorf("turn at tick %d recorded after tick %d", turn.Tick, previous)
+		}
+		index := directionIndex(turn.Direction)
+		if index < 0 {
+			return fmt.Errorf("invalid direction %v at tick %d", turn.Direction, turn.Tick)
+		}
+		if turn.Player < 0 || turn.Player >= engine.MaxPlayers {
+			return fmt.Errorf("invalid player %d at tick %d", turn.Player, turn.Tick)
+		}
+		bw.Write(buf[:binary.PutUvarint(buf, uint64(turn.Tick-previous))])
+		bw.WriteByte(byte(turn.Player<<2 | index))
+		previous = turn.Tick
+	}
+	return bw.Flush()
+}
+
+// Decode reads a replay written by Encode
+func Decode(rd io.Reader) (*Replay, error) {
+	br := bufio.NewReader(rd)
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, header); err != nil || string(header) != magic {
+		return nil, errors.New("not a replay file")
+	}
+	v, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if v != version {
+		return nil, fmt.Errorf("unsupported replay version %d", v)
+	}
+
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxRulesSize {
+		return nil, fmt.Errorf("rules too large (%d bytes)", size)
+	}
+	rules := make([]byte, size)
+	if _, err := io.ReadFull(br, rules); err != nil {
+		return nil, err
+	}
+	r := &Replay{Rules: engine.DefaultRules()}
+	if err := json.Unmarshal(rules, &r.Rules); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	if err := r.Rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	if r.Seed, err = binary.ReadVarint(br); err != nil {
+		return nil, err
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	tick := 0
+	for i := uint64(0); i < count; i++ {
+		delta, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		b, err := br.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		player := int(b >> 2)
+		if player >= r.Rules.PlayerCount() {
+			return nil, fmt.Errorf("invalid player %d in turn %d", player, i)
+		}
+		tick += int(delta)
+		r.Turns = append(r.Turns, Turn{Tick: tick, Player: player, Direction: directions[b&3]})
+	}
+	return r, nil
+}
+
+// Write saves a replay at the given path
+func Write(path string, r *Replay) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Encode(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read loads the replay saved at the given path
+func Read(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// directionIndex returns the index of a direction in directions, or -1 if it isn't one
+func directionIndex(direction vars.Point) int {
+	for i, d := range directions {
+		if d == direction {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,148 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"GoSnake/engine"
+	"GoSnake/level"
+	"GoSnake/vars"
+)
+
+// rules are the rules of the games in the tests, they differ from the default ones
+var rules = func() engine.Rules {
+	r := engine.DefaultRules()
+	r.Board.Width, r.Board.Height = 20, 16
+	r.WinScore = 10
+	return r
+}()
+
+var (
+	up    = vars.Point{X: 0, Y: -1}
+	down  = vars.Point{X: 0, Y: 1}
+	left  = vars.Point{X: -1, Y: 0}
+	right = vars.Point{X: 1, Y: 0}
+)
+
+// file builds the bytes of a replay file, each part being a string written as is, a uint64 written as a
+// uvarint, an int64 as a varint or a byte
+func file(parts ...any) []byte {
+	var b []byte
+	for _, part := range parts {
+		switch part := part.(type) {
+		case string:
+			b = append(b, part...)
+		case uint64:
+			b = binary.AppendUvarint(b, part)
+		case int64:
+			b = binary.AppendVarint(b, part)
+		case byte:
+			b = append(b, part)
+		}
+	}
+	return b
+}
+
+func TestRoundTrip(t *testing.T) {
+	box, err := level.Load(filepath.Join("..", "levels", "box.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	versus := rules
+	versus.Players = engine.MaxPlayers
+	tests := []struct {
+		name   string
+		replay *Replay
+	}{
+		{"no turns", &Replay{Rules: rules, Seed: 1}},
+		{"negative seed", &Replay{Rules: rules, Seed: -42, Turns: []Turn{{Tick: 0, Direction: up}}}},
+		{"default rules", &Replay{Rules: engine.DefaultRules(), Seed: 7}},
+		{"level", &Replay{Rules: rules.WithLevel(box), Seed: 7, Turns: []Turn{{Tick: 4, Direction: down}}}},
+		{"turns", &Replay{Rules: rules, Seed: 1 << 40, Turns: []Turn{
+			{Tick: 0, Direction: up}, {Tick: 0, Direction: left}, {Tick: 3, Direction: down}, {Tick: 300, Direction: right},
+		}}},
+		{"players", &Replay{Rules: versus, Seed: 9, Turns: []Turn{
+			{Tick: 0, Player: 1, Direction: up}, {Tick: 0, Player: 0, Direction: down}, {Tick: 2, Player: 3, Direction: right},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, tt.replay); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.replay) {
+				t.Errorf("got %+v, want %+v", got, tt.replay)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	// Missing rules keep their default value
+	encoded := `{"board":{"width":20,"height":16},"win_score":10}`
+	data := file(magic, uint64(version), uint64(len(encoded)), encoded, int64(-3), uint64(3), uint64(2), byte(0), uint64(0), byte(2), uint64(5), byte(1))
+	want := &Replay{Rules: rules, Seed: -3, Turns: []Turn{{Tick: 2, Direction: up}, {Tick: 2, Direction: left}, {Tick: 7, Direction: down}}}
+	got, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		turns []Turn
+		want  string
+	}{
+		{"turns out of order", []Turn{{Tick: 5, Direction: up}, {Tick: 4, Direction: up}}, "recorded after tick 5"},
+		{"not a direction", []Turn{{Tick: 0, Direction: vars.Point{X: 1, Y: 1}}}, "invalid direction"},
+		{"too many players", []Turn{{Tick: 2, Player: engine.MaxPlayers, Direction: up}}, "invalid player 4 at tick 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Encode(&bytes.Buffer{}, &Replay{Turns: tt.turns})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, "not a replay file"},
+		{"wrong magic", file("GSNX", uint64(version)), "not a replay file"},
+		{"version 0", file(magic, uint64(0)), "unsupported replay version 0"},
+		{"future version", file(magic, uint64(version+1)), "unsupported replay version"},
+		{"rules too large", file(magic, uint64(version), uint64(maxRulesSize+1)), "rules too large"},
+		{"truncated rules", file(magic, uint64(version), uint64(10), "{}"), "EOF"},
+		{"rules not JSON", file(magic, uint64(version), uint64(2), "{]"), "invalid rules"},
+		{"invalid rules", file(magic, uint64(version), uint64(len(`{"win_score":0}`)), `{"win_score":0}`), "invalid rules: the winning score must be positive"},
+		{"player without a snake", file(magic, uint64(version), uint64(2), "{}", int64(0), uint64(1), uint64(0), byte(1<<2)), "invalid player 1 in turn 0"},
+		{"truncated turns", file(magic, uint64(version), uint64(2), "{}", int64(0), uint64(2), uint64(0), byte(0)), "EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(bytes.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Package replay records the input of a game so it can be played again
+// exactly, the seed and the turns are enough to rebuild every step
+package replay
+
+import (
+	"GoSnake/engine"
+	"GoSnake/vars"
+)
+
+// Turn is a direction requested by a player before a given step
+type Turn struct {
+	Tick      int        `json:"tick"`             // The number of steps taken when the turn was requested
+	Player    int        `json:"player,omitempty"` // The player who requested it
+	Direction vars.Point `json:"direction"`        // The requested direction
+}
+
+// Replay holds everything needed to play a game again
+type Replay struct {
+	Rules engine.Rules // The rules the game was played with
+	Seed  int64        // The seed the game was started with
+	Turns []Turn       // The turns requested by the players, in order
+}
+
+// New creates an empty replay for a game started with the given rules and seed
+func New(rules engine.Rules, seed int64) *Replay {
+	return &Replay{Rules: rules, Seed: seed}
+}
+
+// Record adds the turns of the input given to the engine at a tick
+func (r *Replay) Record(tick int, input engine.Input) {
+	for player, turns := range input.Turns {
+		for _, direction := range turns {
+			r.Turns = append(r.Turns, Turn{Tick: tick, Player: player, Direction: direction})
+		}
+	}
+}
+
+// Player feeds the turns of a replay back to the engine
+type Player struct {
+	replay *Replay // The replay being played
+	next   int     // The index of the next turn to play
+}
+
+// NewPlayer creates a player starting at the beginning of the replay
+func NewPlayer(r *Replay) *Player {
+	return &Player{replay: r}
+}
+
+// Seed returns the seed of the game being played
+func (p *Player) Seed() int64 {
+	return p.replay.Seed
+}
+
+// Rules returns the rules of the game being played
+func (p *Player) Rules() engine.Rules {
+	return p.replay.Rules
+}
+
+// Input returns the input to give the engine at a tick, ticks must be asked in order
+func (p *Player) Input(tick int) engine.Input {
+	var input engine.Input
+	for p.next < len(p.replay.Turns) && p.replay.Turns[p.next].Tick <= tick {
+		input.Add(p.replay.Turns[p.next].Player, p.replay.Turns[p.next].Direction)
+		p.next++
+	}
+	return input
+}
+
+// Rewind goes back to the beginning of the replay
+func (p *Player) Rewind() {
+	p.next = 0
+}
@@ -0,0 +1,42 @@
+package replay
+
+import (
+	"reflect"
+	"testing"
+
+	"GoSnake/engine"
+	"GoSnake/rng"
+)
+
+// record plays a game with random turns and returns its last state and its recording
+func record(seed int64) (engine.State, *Replay) {
+	turns := rng.New(seed + 100)
+	state := engine.NewState(rules, seed)
+	rec := New(rules, seed)
+	for !state.GameOver && !state.GameWon && state.Tick < 1000 {
+		var input engine.Input
+		if turns.Intn(3) == 0 {
+			input.Add(0, directions[turns.Intn(len(directions))])
+		}
+		rec.Record(state.Tick, input)
+		state, _ = engine.Step(state, input)
+	}
+	return state, rec
+}
+
+func TestPlayback(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		want, rec := record(seed)
+		player := NewPlayer(rec)
+		for pass := 0; pass < 2; pass++ {
+			state := engine.NewState(player.Rules(), player.Seed())
+			for state.Tick < want.Tick {
+				state, _ = engine.Step(state, player.Input(state.Tick))
+			}
+			if !reflect.DeepEqual(state, want) {
+				t.Fatalf("seed %d, pass %d: the replay ended differently than the game", seed, pass)
+			}
+			player.Rewind()
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package replay
+
+import (
+	"time"
+
+	"GoSnake/engine"
+	"GoSnake/event"
+)
+
+// Result summarises a game simulated from a replay, the score and length are the first player's
+type Result struct {
+	Score    int              // The final score
+	Length   int              // The final length of the snake
+	Ticks    int              // The number of moves made
+	Duration time.Duration    // The time the game lasted when played at normal speed
+	Won      bool             // Whether the game was won
+	Died     bool             // Whether the first player's snake died
+	Cause    event.DeathCause // What killed the first player's snake, when it died
+	Winner   int              // The player who won or outlived the others, -1 if none did
+	Scores   []int            // The final score of each player
+}
+
+// Simulate plays a replay without a window until the game ends. Once the turns run out the snake keeps
+// going straight, the simulation stops if that doesn't end the game within the size of the board.
+// On a wrapping board the snake can go round several times, eating on the way, so the limit starts
+// again from the last food or bonus eaten
+func Simulate(r *Replay) Result {
+	state := engine.NewState(r.Rules, r.Seed)
+	player := NewPlayer(r)
+	var result Result
+
+	last := 0
+	if len(r.Turns) > 0 {
+		last = r.Turns[len(r.Turns)-1].Tick
+	}
+
+	for !state.GameOver && !state.GameWon && state.Tick <= last+r.Rules.Board.Width+r.Rules.Board.Height {
+		result.Duration += state.MoveInterval
+		var events []event.Event
+		state, events = engine.Step(state, player.Input(state.Tick))
+		for _, e := range events {
+			switch e := e.(type) {
+			case event.SnakeDied:
+				if e.Player == 0 {
+					result.Died = true
+					result.Cause = e.Cause
+				}
+			case event.FoodEaten, event.BonusEaten:
+				last = max(last, state.Tick)
+			}
+		}
+	}
+
+	result.Score = state.Scores[0]
+	result.Scores = state.Scores
+	result.Winner = state.Winner
+	result.Length = len(state.Snakes[0].Body)
+	result.Ticks = state.Tick
+	result.Won = state.GameWon
+	return result
+}
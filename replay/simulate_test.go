@@ -0,0 +1,39 @@
+package replay
+
+import (
+	"testing"
+
+	"GoSnake/engine"
+	"GoSnake/event"
+)
+
+func TestSimulate(t *testing.T) {
+	checked := 0
+	for seed := int64(0); seed < 20; seed++ {
+		state, rec := record(seed)
+		if !state.GameOver && !state.GameWon {
+			continue // Stopped by record, the simulation goes on straight past it
+		}
+		checked++
+		result := Simulate(rec)
+		if result.Ticks != state.Tick || result.Score != state.Scores[0] || result.Length != len(state.Snakes[0].Body) ||
+			result.Won != state.GameWon || result.Died != state.GameOver {
+			t.Errorf("seed %d: simulated %+v, the game ended at tick %d with score %d", seed, result, state.Tick, state.Scores[0])
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no recorded game ended")
+	}
+}
+
+func TestSimulateWithoutTurns(t *testing.T) {
+	// The snake goes straight from the middle to the right edge
+	for _, width := range []int{9, 30} {
+		rules := engine.DefaultRules()
+		rules.Board.Width = width
+		result := Simulate(&Replay{Rules: rules, Seed: 1})
+		if !result.Died || result.Cause != event.HitWall || result.Ticks != width-width/2 {
+			t.Errorf("width %d: got %+v, want the snake hitting the wall after %d moves", width, result, width-width/2)
+		}
+	}
+}
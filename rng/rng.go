@@ -0,0 +1,30 @@
+// Package rng provides a seedable random number generator whose whole
+// state is a single number, so games can be reproduced and saved
+package rng
+
+// Rand is a deterministic random number generator based on SplitMix64
+type Rand struct {
+	State uint64 // The internal state, advanced on every draw
+}
+
+// New creates a random number generator from a seed
+func New(seed int64) *Rand {
+	return &Rand{State: uint64(seed)}
+}
+
+// Uint64 returns the next pseudo-random 64-bit number
+func (r *Rand) Uint64() uint64 {
+	r.State += 0x9e3779b97f4a7c15
+	z := r.State
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Intn returns a pseudo-random number in [0, n), it panics if n <= 0
+func (r *Rand) Intn(n int) int {
+	if n <= 0 {
+		panic("rng: invalid argument to Intn")
+	}
+	return int(r.Uint64() % uint64(n))
+}
@@ -0,0 +1,65 @@
+package rng
+
+import "testing"
+
+func TestUint64(t *testing.T) {
+	// The first numbers of the reference SplitMix64 for some seeds
+	tests := []struct {
+		seed int64
+		want []uint64
+	}{
+		{0, []uint64{0xe220a8397b1dcdaf, 0x6e789e6aa1b965f4, 0x06c45d188009454f}},
+		{1234567, []uint64{0x599ed017fb08fc85, 0x2c73f08458540fa5, 0x883ebce5a3f27c77}},
+		{-1, []uint64{0xe4d971771b652c20, 0xe99ff867dbf682c9, 0x382ff84cb27281e9}},
+	}
+	for _, tt := range tests {
+		r := New(tt.seed)
+		for i, want := range tt.want {
+			if got := r.Uint64(); got != want {
+				t.Errorf("seed %d, number %d: got %#x, want %#x", tt.seed, i, got, want)
+			}
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	// A generator rebuilt from the state of another goes on with the same numbers, that's how saved games resume
+	r := New(42)
+	r.Uint64()
+	resumed := Rand{State: r.State}
+	for i := 0; i < 10; i++ {
+		if a, b := r.Uint64(), resumed.Uint64(); a != b {
+			t.Fatalf("number %d: %#x after resuming, want %#x", i, b, a)
+		}
+	}
+}
+
+func TestIntn(t *testing.T) {
+	r := New(7)
+	seen := make([]bool, 6)
+	for i := 0; i < 1000; i++ {
+		n := r.Intn(len(seen))
+		if n < 0 || n >= len(seen) {
+			t.Fatalf("Intn(%d) returned %d", len(seen), n)
+		}
+		seen[n] = true
+	}
+	for n, ok := range seen {
+		if !ok {
+			t.Errorf("Intn(%d) never returned %d in 1000 draws", len(seen), n)
+		}
+	}
+}
+
+func TestIntnPanics(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Intn(%d) didn't panic", n)
+				}
+			}()
+			New(1).Intn(n)
+		}()
+	}
+}
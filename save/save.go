@@ -0,0 +1,193 @@
+// Package save writes an in-progress game to disk and reads it back, so a
+// run can be continued on the next launch
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"GoSnake/engine"
+	"GoSnake/food"
+	"GoSnake/replay"
+	"GoSnake/rng"
+	"GoSnake/vars"
+)
+
+const (
+	Path    = "savegame.json" // Where the game is saved, next to the scores
+	Version = 1               // The version of the format written by Write
+)
+
+// The game modes, a save holds the mode its rules make
+const (
+	Classic = "classic" // A single snake on an open board
+	Wrap    = "wrap"    // A single snake on a board it goes through the edges of
+	Level   = "level"   // A single snake in a level
+	Versus  = "versus"  // Several snakes on the same board
+)
+
+// ErrNoSave is returned by Read when there is no saved game
+var ErrNoSave = errors.New("no saved game")
+
+// file is the format of a saved game on disk
+type file struct {
+	Version      int           `json:"version"`       // The version of the format
+	Mode         string        `json:"mode"`          // The game mode, one of the modes
+	Seed         int64         `json:"seed"`          // The seed the game was started with
+	Tick         int           `json:"tick"`          // The number of steps taken
+	Replay       []replay.Turn `json:"replay"`        // The turns recorded so far
+	Rules        *engine.Rules `json:"rules"`         // The rules of the game
+	RNG          uint64        `json:"rng"`           // The state of the random source
+	Snakes       []snake       `json:"snakes"`        // The snake of each player
+	Foods        []food.Food   `json:"foods"`         // The food, one for all the players or one each, then the deadly food
+	Bonus        food.Bonus    `json:"bonus"`         // The bonus food
+	Scores       []int         `json:"scores"`        // The score of each player
+	MoveInterval int64         `json:"move_interval"` // The time between two moves in nanoseconds
+}
+
+// snake is the format of a snake in a saved game
+type snake struct {
+	Body        []vars.Point `json:"body"`           // The body of the snake, head first
+	Direction   vars.Point   `json:"direction"`      // The direction of the snake
+	Turns       []vars.Point `json:"turns"`          // The turns queued by the player
+	GrowCounter int          `json:"grow_counter"`   // The number of times the snake still has to grow
+	Dead        bool         `json:"dead,omitempty"` // Whether the snake died while the others play on
+}
+
+// Write saves the state of a game and its recording at the given path
+func Write(path string, state engine.State, rec *replay.Replay) error {
+	var turns []replay.Turn
+	if rec != nil {
+		turns = rec.Turns
+	}
+	var snakes []snake
+	for _, s := range state.Snakes {
+		snakes = append(snakes, snake{Body: s.Body, Direction: s.Direction, Turns: s.Turns, GrowCounter: s.GrowCounter, Dead: s.Dead})
+	}
+	data, err := json.MarshalIndent(file{
+		Version:      Version,
+		Mode:         Mode(state.Rules),
+		Seed:         state.Seed,
+		Tick:         state.Tick,
+		Replay:       turns,
+		Rules:        &state.Rules,
+		RNG:          state.RNG.State,
+		Snakes:       snakes,
+		Foods:        state.Foods,
+		Bonus:        state.Bonus,
+		Scores:       state.Scores,
+		MoveInterval: int64(state.MoveInterval),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a half written save
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Mode returns the mode of a game played with some rules, several snakes make a versus game whatever the board
+// and a level makes a level game even if it wraps
+func Mode(rules engine.Rules) string {
+	switch {
+	case rules.PlayerCount() > 1:
+		return Versus
+	case rules.Level != nil:
+		return Level
+	case rules.Board.Wrap:
+		return Wrap
+	}
+	return Classic
+}
+
+// Read loads the game saved at the given path along with its recording, it returns ErrNoSave if there is none.
+func Read(path string) (engine.State, *replay.Replay, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return engine.State{}, nil, ErrNoSave
+	}
+	if err != nil {
+		return engine.State{}, nil, err
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return engine.State{}, nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if f.Version != Version {
+		return engine.State{}, nil, fmt.Errorf("reading %s: unsupported save version %d", path, f.Version)
+	}
+	if f.Mode != Classic && f.Mode != Wrap && f.Mode != Level && f.Mode != Versus {
+		return engine.State{}, nil, fmt.Errorf("reading %s: unknown game mode %q", path, f.Mode)
+	}
+	if f.MoveInterval <= 0 {
+		return engine.State{}, nil, fmt.Errorf("reading %s: invalid move interval %d", path, f.MoveInterval)
+	}
+	if f.Rules == nil {
+		return engine.State{}, nil, fmt.Errorf("reading %s: no rules", path)
+	}
+
+	rules := *f.Rules
+	if err := rules.Validate(); err != nil {
+		return engine.State{}, nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if mode := Mode(rules); f.Mode != mode {
+		return engine.State{}, nil, fmt.Errorf("reading %s: a %s game saved as a %s one", path, mode, f.Mode)
+	}
+	if len(f.Snakes) != rules.PlayerCount() || len(f.Scores) != len(f.Snakes) {
+		return engine.State{}, nil, fmt.Errorf("reading %s: expected %d snakes and scores, got %d and %d", path, rules.PlayerCount(), len(f.Snakes), len(f.Scores))
+	}
+	if len(f.Foods) < rules.FoodSlots() {
+		return engine.State{}, nil, fmt.Errorf("reading %s: expected at least %d foods, got %d", path, rules.FoodSlots(), len(f.Foods))
+	}
+	for i, fd := range f.Foods {
+		if rules.FoodType(fd.Kind).Kind != fd.Kind {
+			return engine.State{}, nil, fmt.Errorf("reading %s: unknown food kind %q", path, fd.Kind)
+		}
+		if i >= rules.FoodSlots() && !rules.FoodType(fd.Kind).Deadly {
+			return engine.State{}, nil, fmt.Errorf("reading %s: food %d is a %q food, only deadly ones come after the %d looked for", path, i+1, fd.Kind, rules.FoodSlots())
+		}
+	}
+	snakes := make([]engine.Snake, len(f.Snakes))
+	for i, s := range f.Snakes {
+		if len(s.Body) == 0 {
+			return engine.State{}, nil, fmt.Errorf("reading %s: snake %d has no body", path, i+1)
+		}
+		snakes[i] = engine.Snake{Body: s.Body, Direction: s.Direction, Turns: s.Turns, GrowCounter: s.GrowCounter, Dead: s.Dead}
+	}
+
+	portals, err := engine.Portals(rules, f.Seed) // They never change, placing them again gives the same ones
+	if err != nil {
+		return engine.State{}, nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return engine.State{
+		Rules:        rules,
+		Seed:         f.Seed,
+		Tick:         f.Tick,
+		RNG:          rng.Rand{State: f.RNG},
+		Snakes:       snakes,
+		Foods:        f.Foods,
+		Bonus:        f.Bonus,
+		Portals:      portals,
+		Scores:       f.Scores,
+		MoveInterval: time.Duration(f.MoveInterval),
+		Winner:       -1,
+	}, &replay.Replay{Rules: rules, Seed: f.Seed, Turns: f.Replay}, nil
+}
+
+// Remove deletes the game saved at the given path, if any
+func Remove(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,195 @@
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"GoSnake/engine"
+	"GoSnake/food"
+	"GoSnake/level"
+	"GoSnake/replay"
+	"GoSnake/rng"
+	"GoSnake/vars"
+)
+
+// directions are the turns the test player picks from
+var directions = []vars.Point{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}}
+
+// rules differ from the default ones, so reading a save doesn't get them right by chance
+var rules = func() engine.Rules {
+	r := engine.DefaultRules()
+	r.Board.Width, r.Board.Height = 30, 20
+	r.WinScore = 10
+	r.Bonus = engine.BonusRules{Chance: 50, Lifetime: 10, Points: 5}
+	r.Portals = 2
+	r.Foods = []food.Type{{Kind: food.Normal, Weight: 3, Points: 1, Grow: 1}, {Kind: "gold", Weight: 1, Points: 3, Grow: 2}, {Kind: "poison", Weight: 1, Deadly: true}}
+	return r
+}()
+
+// played returns a game and its recording after a few moves with random turns, stopping before it ends
+func played(seed int64) (engine.State, *replay.Replay) {
+	state := engine.NewState(rules, seed)
+	rec := replay.New(rules, seed)
+	turns := rng.New(seed + 1)
+	for i := 0; i < 40; i++ {
+		var input engine.Input
+		input.Add(0, directions[turns.Intn(len(directions))])
+		next, _ := engine.Step(state, input)
+		if next.GameOver || next.GameWon {
+			break
+		}
+		rec.Record(state.Tick, input)
+		state = next
+	}
+	return state, rec
+}
+
+func TestRoundTrip(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		state, rec := played(seed)
+		if seed%2 == 1 {
+			state.Bonus = food.Bonus{Position: vars.Point{X: 2, Y: 3}, Spawned: state.Tick - 1, Expires: state.Tick + 9, Active: true}
+		} else {
+			state.Foods = append(state.Foods[:1:1], food.Food{Position: vars.Point{X: 4, Y: 1}, Kind: "poison", Expires: state.Tick + 5})
+		}
+		path := filepath.Join(t.TempDir(), Path)
+		if err := Write(path, state, rec); err != nil {
+			t.Fatal(err)
+		}
+		got, gotRec, err := Read(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, state) {
+			t.Errorf("seed %d: read %+v, want %+v", seed, got, state)
+		}
+		if !reflect.DeepEqual(gotRec, rec) {
+			t.Errorf("seed %d: read the recording %+v, want %+v", seed, gotRec, rec)
+		}
+	}
+}
+
+func TestRoundTripVersus(t *testing.T) {
+	versus := rules
+	versus.Players, versus.SeparateFood = 3, true
+	state := engine.NewState(versus, 4)
+	state.Snakes[1].Dead = true
+	state.Scores[2] = 5
+	path := filepath.Join(t.TempDir(), Path)
+	if err := Write(path, state, replay.New(versus, 4)); err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, state) {
+		t.Errorf("read %+v, want %+v", got, state)
+	}
+}
+
+func TestWriteMode(t *testing.T) {
+	wrap := rules
+	wrap.Board.Wrap = true
+	box, err := level.Load(filepath.Join("..", "levels", "box.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	versus := rules
+	versus.Players, versus.SeparateFood = 2, true
+	for _, r := range []engine.Rules{rules, wrap, rules.WithLevel(box), versus} {
+		path := filepath.Join(t.TempDir(), Path)
+		state := engine.NewState(r, 1)
+		if err := Write(path, state, nil); err != nil {
+			t.Fatal(err)
+		}
+		got, _, err := Read(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.Rules, state.Rules) {
+			t.Errorf("read the rules %+v, want %+v", got.Rules, state.Rules)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `"mode": "` + Mode(r) + `"`; !strings.Contains(string(data), want) {
+			t.Errorf("the save doesn't hold %s", want)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	const (
+		snake = `"snakes":[{"body":[{"X":3,"Y":3}],"direction":{"X":1,"Y":0}}]`
+		foods = `"foods":[{"position":{"X":5,"Y":5},"kind":"normal"}],"scores":[0]`
+		two   = `"snakes":[{"body":[{"X":3,"Y":3}]},{"body":[{"X":6,"Y":6}]}]`
+	)
+	classic, err := json.Marshal(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versus := rules
+	versus.Players, versus.SeparateFood = 2, true
+	separate, err := json.Marshal(versus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		save string
+		want string
+	}{
+		{"not JSON", `{"version":`, "unexpected end"},
+		{"version 0", `{"version":0,"mode":"classic",` + snake + `,` + foods + `,"move_interval":1}`, "unsupported save version 0"},
+		{"future version", `{"version":2,"mode":"classic",` + snake + `,` + foods + `,"move_interval":1}`, "unsupported save version 2"},
+		{"unknown mode", `{"version":1,"mode":"race",` + snake + `,` + foods + `,"move_interval":1}`, `unknown game mode "race"`},
+		{"wrong mode", `{"version":1,"mode":"wrap",` + snake + `,` + foods + `,"rules":` + string(classic) + `,"move_interval":1}`, "a classic game saved as a wrap one"},
+		{"unknown food", `{"version":1,"mode":"classic",` + snake + `,"foods":[{"kind":"silver"}],"scores":[0],"rules":` + string(classic) + `,"move_interval":1}`, `unknown food kind "silver"`},
+		{"no snake", `{"version":1,"mode":"classic",` + foods + `,"rules":` + string(classic) + `,"move_interval":1}`, "expected 1 snakes and scores, got 0 and 1"},
+		{"snake without a score", `{"version":1,"mode":"versus",` + two + `,"foods":[{"kind":"normal"},{"kind":"normal"}],"scores":[0],"rules":` + string(separate) + `,"move_interval":1}`, "expected 2 snakes and scores, got 2 and 1"},
+		{"shared food", `{"version":1,"mode":"versus",` + two + `,"foods":[{"kind":"normal"}],"scores":[0,0],"rules":` + string(separate) + `,"move_interval":1}`, "expected at least 2 foods, got 1"},
+		{"extra food not deadly", `{"version":1,"mode":"classic",` + snake + `,"foods":[{"kind":"normal"},{"kind":"gold"}],"scores":[0],"rules":` + string(classic) + `,"move_interval":1}`, `food 2 is a "gold" food, only deadly ones come after the 1 looked for`},
+		{"no body", `{"version":1,"mode":"classic","snakes":[{}],` + foods + `,"rules":` + string(classic) + `,"move_interval":1}`, "snake 1 has no body"},
+		{"no move interval", `{"version":1,"mode":"classic",` + snake + `,` + foods + `,"rules":{}}`, "invalid move interval 0"},
+		{"no rules", `{"version":1,"mode":"classic",` + snake + `,` + foods + `,"move_interval":1}`, "no rules"},
+		{"invalid rules", `{"version":1,"mode":"classic",` + snake + `,` + foods + `,"rules":{},"move_interval":1}`, "the board must be at least 2x2 cells"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), Path)
+			if err := os.WriteFile(path, []byte(tt.save), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, _, err := Read(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), Path)
+	if _, _, err := Read(path); !errors.Is(err, ErrNoSave) {
+		t.Errorf("reading a missing save: got %v, want %v", err, ErrNoSave)
+	}
+	if err := Write(path, engine.NewState(rules, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Read(path); !errors.Is(err, ErrNoSave) {
+		t.Errorf("reading a removed save: got %v, want %v", err, ErrNoSave)
+	}
+	if err := Remove(path); err != nil {
+		t.Errorf("removing a missing save: %v", err)
+	}
+}
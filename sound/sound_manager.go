@@ -4,40 +4,60 @@ import (
 	"log"
 	"os"
 
+	"GoSnake/config"
+	"GoSnake/event"
+
 	"github.com/hajimehoshi/ebiten/audio"
 	"github.com/hajimehoshi/ebiten/audio/mp3"
 )
 
 // AudioManager represents an audio manager
 type AudioManager struct {
-	ctx             *audio.Context // The audio context
-	eatSoundPlayer  *audio.Player  // The audio player for the eat sound
-	eatSoundFile    *os.File       // The file for the eat sound
-	loseSoundPlayer *audio.Player  // The audio player for the lose sound
-	loseSoundFile   *os.File       // The file for the lose sound
-	winSoundPlayer  *audio.Player  // The audio player for the win sound
-	winSoundFile    *os.File       // The file for the win sound
+	ctx             *audio.Context           // The audio context
+	eatSoundPlayer  *audio.Player            // The audio player for the eat sound
+	eatSoundFile    *os.File                 // The file for the eat sound
+	loseSoundPlayer *audio.Player            // The audio player for the lose sound
+	loseSoundFile   *os.File                 // The file for the lose sound
+	winSoundPlayer  *audio.Player            // The audio player for the win sound
+	winSoundFile    *os.File                 // The file for the win sound
+	foodPlayers     map[string]*audio.Player // The audio player for each kind of food with its own sound
+	foodFiles       []*os.File               // The files for the sounds of the kinds of food
 }
 
-// NewAudioManager creates a new AudioManager object
-func NewAudioManager(ctx *audio.Context) *AudioManager {
+// NewAudioManager creates a new AudioManager object playing the sounds of the audio settings
+func NewAudioManager(ctx *audio.Context, settings config.Audio) *AudioManager {
 	am := &AudioManager{ctx: ctx}
 	var err error
 	// Load the eat sound
-	am.eatSoundPlayer, am.eatSoundFile, err = loadAudioPlayer(ctx, "sound/eatSound.mp3")
+	am.eatSoundPlayer, am.eatSoundFile, err = loadAudioPlayer(ctx, settings.Eat)
 	if err != nil {
 		log.Fatal(err)
 	}
 	// Load the lose sound
-	am.loseSoundPlayer, am.loseSoundFile, err = loadAudioPlayer(ctx, "sound/loseSound.mp3")
+	am.loseSoundPlayer, am.loseSoundFile, err = loadAudioPlayer(ctx, settings.Lose)
 	if err != nil {
 		log.Fatal(err)
 	}
 	// Load the win sound
-	am.winSoundPlayer, am.winSoundFile, err = loadAudioPlayer(ctx, "sound/winSound.mp3")
+	am.winSoundPlayer, am.winSoundFile, err = loadAudioPlayer(ctx, settings.Win)
 	if err != nil {
 		log.Fatal(err)
 	}
+	// Load the sounds of the kinds of food
+	am.foodPlayers = map[string]*audio.Player{}
+	for kind, path := range settings.Foods {
+		player, file, err := loadAudioPlayer(ctx, path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		player.SetVolume(settings.Volume)
+		am.foodPlayers[kind] = player
+		am.foodFiles = append(am.foodFiles, file)
+	}
+	// Set the volume of every sound
+	am.eatSoundPlayer.SetVolume(settings.Volume)
+	am.loseSoundPlayer.SetVolume(settings.Volume)
+	am.winSoundPlayer.SetVolume(settings.Volume)
 	return am
 }
 
@@ -47,17 +67,43 @@ func (am *AudioManager) PlayEatSound() {
 	am.eatSoundPlayer.Play()   // Play the audio
 }
 
+// PlayFoodSound plays the sound of a kind of food, or the eat sound if it has none
+func (am *AudioManager) PlayFoodSound(kind string) {
+	player, ok := am.foodPlayers[kind]
+	if !ok {
+		am.PlayEatSound()
+		return
+	}
+	player.Rewind()
+	player.Play()
+}
+
 // PlayLoseSound plays the lose sound
 func (am *AudioManager) PlayLoseSound() {
 	am.loseSoundPlayer.Rewind() // Rewind the audio player to the start
 	am.loseSoundPlayer.Play()   // Play the audio
 }
 
+// PlayWinSound plays the win sound
 func (am *AudioManager) PlayWinSound() {
 	am.winSoundPlayer.Rewind() // Rewind the audio player to the start
 	am.winSoundPlayer.Play()   // Play the audio
 }
 
+// HandleEvent plays the sound matching a game event
+func (am *AudioManager) HandleEvent(e event.Event) {
+	switch e := e.(type) {
+	case event.FoodEaten:
+		am.PlayFoodSound(e.Kind)
+	case event.BonusEaten:
+		am.PlayFoodSound("bonus")
+	case event.SnakeDied:
+		am.PlayLoseSound()
+	case event.GameWon:
+		am.PlayWinSound()
+	}
+}
+
 // loadAudioPlayer loads an audio player from a file
 func loadAudioPlayer(ctx *audio.Context, filePath string) (*audio.Player, *os.File, error) {
 	f, err := os.Open(filePath) // Open the audio file
@@ -84,4 +130,7 @@ func loadAudioPlayer(ctx *audio.Context, filePath string) (*audio.Player, *os.Fi
 func (am *AudioManager) Close() {
 	am.eatSoundFile.Close()  // Close the eat sound file
 	am.loseSoundFile.Close() // Close the lose sound file
+	for _, f := range am.foodFiles {
+		f.Close() // Close the food sound files
+	}
 }
@@ -1,11 +1,56 @@
 package vars
 
-const (
-	ScreenWidth  = 320
-	ScreenHeight = 240
-	TileSize     = 5
-)
+// DefaultBoard is the board the game is played on unless told otherwise
+var DefaultBoard = Board{Width: 64, Height: 48}
+
+// DefaultTileSize is the size of a cell on screen unless told otherwise, in pixels
+const DefaultTileSize = 5
 
 type Point struct {
 	X, Y int
 }
+
+// Board describes the size of the playing field
+type Board struct {
+	Width  int  `json:"width"`  // The number of cells in a row
+	Height int  `json:"height"` // The number of cells in a column
+	Wrap   bool `json:"wrap"`   // Whether leaving the board through an edge enters it from the opposite edge
+}
+
+// Contains reports whether a point is a cell of the board
+func (b Board) Contains(p Point) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < b.Width && p.Y < b.Height
+}
+
+// Neighbor returns the cell next to a point in a direction, on a wrapping board it's always a cell of the board
+func (b Board) Neighbor(p, direction Point) Point {
+	n := Point{X: p.X + direction.X, Y: p.Y + direction.Y}
+	if b.Wrap {
+		n.X = (n.X%b.Width + b.Width) % b.Width
+		n.Y = (n.Y%b.Height + b.Height) % b.Height
+	}
+	return n
+}
+
+// Distance returns the number of moves between two cells, going across the edges when the board wraps
+func (b Board) Distance(p, q Point) int {
+	dx, dy := abs(p.X-q.X), abs(p.Y-q.Y)
+	if b.Wrap {
+		dx = min(dx, b.Width-dx)
+		dy = min(dy, b.Height-dy)
+	}
+	return dx + dy
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Center returns the cell in the middle of the board
+func (b Board) Center() Point {
+	return Point{X: b.Width / 2, Y: b.Height / 2}
+}